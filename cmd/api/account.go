@@ -0,0 +1,281 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"event-api-app/internal/database"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// accountPurgeInterval is how often accounts past their grace period are deleted.
+	accountPurgeInterval = time.Hour
+	// accountDeletionReauthWindow is how recently the current session must have
+	// signed in to delete the account without a password.
+	accountDeletionReauthWindow = 10 * time.Minute
+)
+
+type accountExport struct {
+	ExportedAt  time.Time          `json:"exported_at"`
+	Profile     *database.User     `json:"profile"`
+	UserProfile *database.Profile  `json:"user_profile"`
+	Events      []*database.Event  `json:"events"`
+	Attendances []*database.Event  `json:"attendances"`
+	APIKeys     []*database.APIKey `json:"api_keys"`
+}
+
+type deleteAccountRequest struct {
+	Password string `json:"password"`
+	Mode     string `json:"mode" binding:"omitempty,oneof=anonymize cascade"`
+}
+
+type deleteAccountResponse struct {
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at"`
+	DeletionMode        string    `json:"deletion_mode"`
+}
+
+// exportAccount returns everything stored about the current user
+//
+// @Summary Export personal data
+// @Description Download the account, public profile, owned events, attendances and API keys of the current user as JSON or as a ZIP of JSON files
+// @Tags user
+// @Produce json
+// @Produce application/zip
+// @Param format query string false "json (default) or zip"
+// @Success 200 {object} accountExport
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/user/export [get]
+func (app *application) exportAccount(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "zip" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or zip"})
+		return
+	}
+
+	user := app.GetUserFromContext(c)
+
+	events, err := app.models.Events.GetByOwner(user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export account"})
+		return
+	}
+
+	attendances, err := app.models.Attendees.GetAllEventsForUser(user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export account"})
+		return
+	}
+
+	keys, err := app.models.APIKeys.GetAllForUser(user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export account"})
+		return
+	}
+
+	profile, err := app.models.Profiles.Get(user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export account"})
+		return
+	}
+	if profile != nil {
+		app.withAvatarURLs(profile)
+	}
+
+	export := accountExport{
+		ExportedAt:  time.Now().UTC(),
+		Profile:     user,
+		UserProfile: profile,
+		Events:      events,
+		Attendances: attendances,
+		APIKeys:     keys,
+	}
+
+	filename := fmt.Sprintf("account-%d-export", user.Id)
+
+	if format == "json" {
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`.json"`)
+		c.JSON(http.StatusOK, export)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`.zip"`)
+	c.Header("Content-Type", "application/zip")
+	c.Status(http.StatusOK)
+
+	// ZIP 內每個部分各自一個 JSON 檔
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", export.Profile},
+		{"user_profile.json", export.UserProfile},
+		{"events.json", export.Events},
+		{"attendances.json", export.Attendances},
+		{"api_keys.json", export.APIKeys},
+	}
+
+	archive := zip.NewWriter(c.Writer)
+	for _, file := range files {
+		w, err := archive.Create(file.name)
+		if err != nil {
+			log.Printf("export account %d: %v", user.Id, err)
+			return
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			log.Printf("export account %d: %v", user.Id, err)
+			return
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		log.Printf("export account %d: %v", user.Id, err)
+	}
+}
+
+// deleteAccount schedules the current user's account for deletion
+//
+// @Summary Delete account
+// @Description Schedule the account for deletion after a grace period. With mode "anonymize" (default) owned events are kept without an owner; with "cascade" they are deleted too.
+// @Description Requires the current password, or for accounts without one (magic link, passkey, OIDC, LDAP or SCIM), a sign-in within the last 10 minutes.
+// @Description Every session is logged out and every API key is revoked. Logging in again and calling /auth/user/restore cancels the deletion.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param request body deleteAccountRequest false "Current password (optional after a recent sign-in) and deletion mode"
+// @Success 202 {object} deleteAccountResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/user [delete]
+func (app *application) deleteAccount(c *gin.Context) {
+	var req deleteAccountRequest
+
+	// 沒有密碼的帳號可以不帶 body 直接刪除
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if req.Mode == "" {
+		req.Mode = database.DeletionModeAnonymize
+	}
+
+	user := app.GetUserFromContext(c)
+
+	// 有提供密碼時驗證密碼；否則要求目前的 session 是最近才重新登入的
+	if req.Password != "" {
+		if !app.verifyPassword(user, req.Password) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Password is incorrect"})
+			return
+		}
+	} else {
+		session, err := app.models.Sessions.Get(app.GetClaimsFromContext(c).SessionId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule account deletion"})
+			return
+		}
+
+		if session == nil || time.Since(session.CreatedAt) > accountDeletionReauthWindow {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Enter your password or sign in again to delete your account"})
+			return
+		}
+	}
+
+	scheduledAt := time.Now().Add(app.deletionGrace)
+
+	if err := app.models.Users.ScheduleDeletion(user.Id, scheduledAt, req.Mode); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule account deletion"})
+		return
+	}
+
+	if err := app.models.Sessions.RevokeAllForUser(user.Id, 0); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule account deletion"})
+		return
+	}
+
+	if err := app.models.APIKeys.DeleteAllForUser(user.Id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule account deletion"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, deleteAccountResponse{
+		DeletionScheduledAt: scheduledAt,
+		DeletionMode:        req.Mode,
+	})
+}
+
+// restoreAccount cancels a pending account deletion
+//
+// @Summary Restore account
+// @Description Cancel a scheduled account deletion during the grace period
+// @Tags user
+// @Produce json
+// @Success 200 {object} database.User
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/user/restore [post]
+func (app *application) restoreAccount(c *gin.Context) {
+	user := app.GetUserFromContext(c)
+
+	if user.DeletionScheduledAt == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Account is not scheduled for deletion"})
+		return
+	}
+
+	if err := app.models.Users.CancelDeletion(user.Id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore account"})
+		return
+	}
+
+	user.DeletionScheduledAt = nil
+	user.DeletionMode = ""
+
+	c.JSON(http.StatusOK, user)
+}
+
+// purgeDeletedAccounts deletes accounts whose grace period has ended, once
+// per accountPurgeInterval, for as long as the server runs.
+func (app *application) purgeDeletedAccounts() {
+	ticker := time.NewTicker(accountPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		// 頭像檔案不在資料庫中，需在帳號刪除後另外移除
+		avatars, err := app.models.Profiles.GetAvatarKeysDueForDeletion()
+		if err != nil {
+			log.Printf("purge deleted accounts: %v", err)
+		}
+
+		deleted, err := app.models.Users.PurgeDueDeletions()
+		if err != nil {
+			log.Printf("purge deleted accounts: %v", err)
+		} else {
+			for _, key := range avatars {
+				app.deleteAvatarFiles(key)
+			}
+
+			if deleted > 0 {
+				log.Printf("purged %d deleted accounts", deleted)
+			}
+		}
+
+		<-ticker.C
+	}
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"event-api-app/internal/database"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+type userListResponse struct {
+	Users    []*database.User `json:"users"`
+	Total    int              `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"page_size"`
+}
+
+type setRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// parseOptionalBool returns nil for an empty query value so the filter is skipped.
+func parseOptionalBool(value string) (*bool, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// getTargetUser loads the user named by the :id path parameter, writing an
+// error response and returning nil when it cannot.
+func (app *application) getTargetUser(c *gin.Context) *database.User {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return nil
+	}
+
+	user, err := app.models.Users.Get(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
+		return nil
+	}
+
+	if user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return nil
+	}
+
+	return user
+}
+
+// rejectSelf stops administrators from locking themselves out.
+func (app *application) rejectSelf(c *gin.Context, target *database.User) bool {
+	if app.GetUserFromContext(c).Id == target.Id {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot perform this action on your own account"})
+		return true
+	}
+	return false
+}
+
+// listUsers lists users for administrators
+//
+// @Summary List users
+// @Description Search and paginate users (requires users.manage)
+// @Tags admin
+// @Produce json
+// @Param q query string false "Search name or email"
+// @Param role query string false "Filter by role"
+// @Param verified query bool false "Filter by verification status"
+// @Param disabled query bool false "Filter by disabled status"
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} userListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /admin/users [get]
+func (app *application) listUsers(c *gin.Context) {
+	filter := database.UserFilter{
+		Search: c.Query("q"),
+		Role:   c.Query("role"),
+	}
+
+	var err error
+	if filter.Verified, err = parseOptionalBool(c.Query("verified")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid verified filter"})
+		return
+	}
+
+	if filter.Disabled, err = parseOptionalBool(c.Query("disabled")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid disabled filter"})
+		return
+	}
+
+	filter.Page, err = strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || filter.Page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return
+	}
+
+	filter.PageSize, err = strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(defaultPageSize)))
+	if err != nil || filter.PageSize < 1 || filter.PageSize > maxPageSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page_size"})
+		return
+	}
+
+	users, total, err := app.models.Users.List(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, userListResponse{
+		Users:    users,
+		Total:    total,
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+	})
+}
+
+// getUser returns a single user for administrators
+//
+// @Summary Get user
+// @Description Get a user by ID (requires users.manage)
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} database.User
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /admin/users/{id} [get]
+func (app *application) getUser(c *gin.Context) {
+	user := app.getTargetUser(c)
+	if user == nil {
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// setUserRole changes a user's role
+//
+// @Summary Change user role
+// @Description Assign a role to a user (requires users.manage)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body setRoleRequest true "New role"
+// @Success 200 {object} database.User
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /admin/users/{id}/role [put]
+func (app *application) setUserRole(c *gin.Context) {
+	var req setRoleRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := app.getTargetUser(c)
+	if user == nil || app.rejectSelf(c, user) {
+		return
+	}
+
+	exists, err := app.models.Roles.Exists(req.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve role"})
+		return
+	}
+
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown role"})
+		return
+	}
+
+	if err := app.models.Users.SetRole(user.Id, req.Role); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
+		return
+	}
+
+	user.Role = req.Role
+	c.JSON(http.StatusOK, user)
+}
+
+// forceVerifyUser marks a user's email as verified
+//
+// @Summary Force-verify user
+// @Description Mark a user's email as verified without a token (requires users.manage)
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} database.User
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /admin/users/{id}/verify [post]
+func (app *application) forceVerifyUser(c *gin.Context) {
+	user := app.getTargetUser(c)
+	if user == nil {
+		return
+	}
+
+	if err := app.models.Users.MarkVerified(user.Id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify user"})
+		return
+	}
+
+	user.Verified = true
+	c.JSON(http.StatusOK, user)
+}
+
+// disableUser blocks a user from logging in or using existing tokens
+//
+// @Summary Disable user
+// @Description Disable an account and revoke its refresh tokens (requires users.manage)
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /admin/users/{id}/disable [post]
+func (app *application) disableUser(c *gin.Context) {
+	user := app.getTargetUser(c)
+	if user == nil || app.rejectSelf(c, user) {
+		return
+	}
+
+	if err := app.models.Users.SetDisabled(user.Id, true); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable user"})
+		return
+	}
+
+	if err := app.models.Sessions.RevokeAllForUser(user.Id, 0); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User disabled"})
+}
+
+// enableUser re-enables a disabled user
+//
+// @Summary Enable user
+// @Description Re-enable a disabled account (requires users.manage)
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /admin/users/{id}/enable [post]
+func (app *application) enableUser(c *gin.Context) {
+	user := app.getTargetUser(c)
+	if user == nil {
+		return
+	}
+
+	if err := app.models.Users.SetDisabled(user.Id, false); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User enabled"})
+}
+
+// deleteUser permanently deletes a user
+//
+// @Summary Delete user
+// @Description Permanently delete a user account immediately (requires users.manage). Events they own are kept without an owner.
+// @Tags admin
+// @Param id path int true "User ID"
+// @Success 204 "User deleted"
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /admin/users/{id} [delete]
+func (app *application) deleteUser(c *gin.Context) {
+	user := app.getTargetUser(c)
+	if user == nil || app.rejectSelf(c, user) {
+		return
+	}
+
+	if err := app.deleteUserAccount(user.Id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
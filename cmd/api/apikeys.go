@@ -0,0 +1,146 @@
+package main
+
+import (
+	"event-api-app/internal/database"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// API key scopes. Bearer JWTs are not limited by scopes.
+const (
+	scopeEventsRead     = "events:read"
+	scopeEventsWrite    = "events:write"
+	scopeAttendeesRead  = "attendees:read"
+	scopeAttendeesWrite = "attendees:write"
+)
+
+var validScopes = map[string]bool{
+	scopeEventsRead:     true,
+	scopeEventsWrite:    true,
+	scopeAttendeesRead:  true,
+	scopeAttendeesWrite: true,
+}
+
+type createAPIKeyRequest struct {
+	Name          string   `json:"name" binding:"required,min=2"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1,max=365"`
+}
+
+type createAPIKeyResponse struct {
+	Key    string          `json:"key"`
+	APIKey database.APIKey `json:"api_key"`
+}
+
+// createAPIKey mints a personal API key
+//
+// @Summary Create API key
+// @Description Create a named API key with scopes and an optional expiry. The key is only shown once.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param request body createAPIKeyRequest true "API key settings"
+// @Success 201 {object} createAPIKeyResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/api-keys [post]
+func (app *application) createAPIKey(c *gin.Context) {
+	var req createAPIKeyRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, scope := range req.Scopes {
+		if !validScopes[scope] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown scope: " + scope})
+			return
+		}
+	}
+
+	user := app.GetUserFromContext(c)
+
+	key := database.APIKey{
+		UserId: user.Id,
+		Name:   req.Name,
+		Scopes: req.Scopes,
+	}
+
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().Add(time.Duration(req.ExpiresInDays) * 24 * time.Hour)
+		key.ExpiresAt = &expiresAt
+	}
+
+	plaintext, err := app.models.APIKeys.Insert(&key)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, createAPIKeyResponse{Key: plaintext, APIKey: key})
+}
+
+// getAPIKeys lists the current user's API keys
+//
+// @Summary List API keys
+// @Description List the current user's API keys without their secret values
+// @Tags api-keys
+// @Produce json
+// @Success 200 {array} database.APIKey
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/api-keys [get]
+func (app *application) getAPIKeys(c *gin.Context) {
+	user := app.GetUserFromContext(c)
+
+	keys, err := app.models.APIKeys.GetAllForUser(user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve API keys"})
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// deleteAPIKey revokes one of the current user's API keys
+//
+// @Summary Delete API key
+// @Description Revoke an API key
+// @Tags api-keys
+// @Param id path int true "API key ID"
+// @Success 204 "API key deleted"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/api-keys/{id} [delete]
+func (app *application) deleteAPIKey(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return
+	}
+
+	user := app.GetUserFromContext(c)
+
+	deleted, err := app.models.APIKeys.Delete(id, user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete API key"})
+		return
+	}
+
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -1,43 +1,53 @@
 package main
 
 import (
+	"errors"
 	"event-api-app/internal/database"
+	"log"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
 )
 
+const emailChangeTTL = 24 * time.Hour
+
 type registerRequest struct {
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=8"`
+	Password string `json:"password" binding:"required"`
 	Name     string `json:"name" binding:"required,min=2"`
 }
 
 type loginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=8"`
+	Password string `json:"password" binding:"required"`
 }
 
 type loginResponse struct {
-	Token string        `json:"token"`
-	User  database.User `json:"user"`
+	Token        string        `json:"token"`
+	RefreshToken string        `json:"refresh_token"`
+	ExpiresIn    int           `json:"expires_in"`
+	User         database.User `json:"user"`
 }
 
 // login authenticates user and returns JWT token
 //
 // @Summary User login
-// @Description Authenticate user with email and password
+// @Description Authenticate user with email and password against the configured providers (local, LDAP). Accounts with MFA enabled get an mfa_token to complete at /auth/login/mfa.
 // @Tags authentication
 // @Accept json
 // @Produce json
 // @Param credentials body loginRequest true "User login credentials"
-// @Success 200 {object} database.User
+// @Success 200 {object} loginResponse
+// @Success 202 {object} mfaChallengeResponse
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 429 {object} map[string]string
 // @Failure 500 {object} map[string]string
+// @Failure 502 {object} map[string]string
 // @Router /auth/login [post]
 func (app *application) login(c *gin.Context) {
 	var auth loginRequest
@@ -47,43 +57,116 @@ func (app *application) login(c *gin.Context) {
 		return
 	}
 
-	existingUser, err := app.models.Users.GetByEmail(auth.Email)
+	ip := c.ClientIP()
 
-	if existingUser == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+	// 檢查此 email 或 IP 是否因多次登入失敗而暫時被封鎖
+	blockedUntil, err := app.loginBlockedUntil(auth.Email, ip)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	if !blockedUntil.IsZero() {
+		c.Header("Retry-After", strconv.Itoa(int(time.Until(blockedUntil).Seconds())+1))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed login attempts, please try again later"})
 		return
 	}
 
+	// 依 AUTH_PROVIDERS 的順序（local、ldap）驗證帳號密碼
+	user, err := app.authenticate(auth.Email, auth.Password)
+	if errors.Is(err, errAuthUnavailable) {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Authentication provider is unavailable"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
 		return
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(existingUser.Password), []byte(auth.Password))
+	if user == nil {
+		existingUser, err := app.models.Users.GetByEmail(auth.Email)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+			return
+		}
+
+		if err := app.recordLoginFailure(auth.Email, ip, existingUser); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+			return
+		}
 
-	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
 		return
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": existingUser.Id,
-		"expr":    time.Now().Add(time.Hour * 72).Unix(),
-	})
+	if err := app.models.LoginAttempts.Reset(emailLoginKey(auth.Email)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
 
-	tokenString, err := token.SignedString([]byte(app.jwtSecret))
+	app.completeLogin(c, user)
+}
+
+// completeLogin finishes a login whose password check passed. Users with MFA
+// enabled receive a short-lived challenge token instead of a token pair.
+func (app *application) completeLogin(c *gin.Context, user *database.User) {
+	if rejectDisabled(c, user) {
+		return
+	}
+
+	mfaEnabled, err := app.models.MFA.IsEnabled(user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	if mfaEnabled {
+		mfaToken, err := app.newSignedToken(user.Id, tokenUseMFA, mfaChallengeTTL)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong, not able to generate token"})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, mfaChallengeResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+			ExpiresIn:   int(mfaChallengeTTL.Seconds()),
+		})
+		return
+	}
+
+	app.issueLoginResponse(c, user)
+}
+
+// rejectDisabled responds with 403 and returns true if the account is disabled.
+func rejectDisabled(c *gin.Context, user *database.User) bool {
+	if user.IsDisabled() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account is disabled"})
+		return true
+	}
+	return false
+}
+
+// issueLoginResponse responds with a new token pair for a fully authenticated user.
+func (app *application) issueLoginResponse(c *gin.Context, user *database.User) {
+	tokens, err := app.issueTokens(c, user.Id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong, not able to generate token"})
 		return
 	}
 
-	c.JSON(http.StatusOK, loginResponse{Token: tokenString, User: *existingUser})
+	c.JSON(http.StatusOK, loginResponse{
+		Token:        tokens.Token,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User:         *user,
+	})
 }
 
 // registerUser creates a new user account
 //
 // @Summary User registration
-// @Description Create a new user account
+// @Description Create a new user account. The password must satisfy the password policy.
 // @Tags authentication
 // @Accept json
 // @Produce json
@@ -101,20 +184,23 @@ func (app *application) registerUser(c *gin.Context) {
 		return
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(register.Password), bcrypt.DefaultCost)
+	if !app.checkPasswordPolicy(c, register.Password, register.Email, register.Name) {
+		return
+	}
+
+	hashedPassword, err := app.passwords.Hash(register.Password)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
 		return
 	}
 
-	register.Password = string(hashedPassword)
+	register.Password = hashedPassword
 
 	user := database.User{
 		Email:    register.Email,
 		Password: register.Password,
 		Name:     register.Name,
-		Role:     "user", // Default role is "user"
 	}
 
 	err = app.models.Users.Insert(&user)
@@ -124,26 +210,73 @@ func (app *application) registerUser(c *gin.Context) {
 		return
 	}
 
+	// 帳號已建立；驗證信失敗時用戶可透過 /auth/verify/resend 重新寄送
+	if err := app.sendVerificationEmail(&user); err != nil {
+		log.Printf("send verification email to user %d: %v", user.Id, err)
+	}
+
 	c.JSON(http.StatusCreated, user)
 }
 
+// provisionUser creates a verified account for someone who signed in through
+// an external identity provider. The password is random and never revealed,
+// so the account can only sign in through the provider, a magic link or a
+// password reset.
+func (app *application) provisionUser(email, name string) (*database.User, error) {
+	if name == "" {
+		name, _, _ = strings.Cut(email, "@")
+	}
+
+	secret, err := newTokenId()
+	if err != nil {
+		return nil, err
+	}
+
+	password, err := app.passwords.Hash(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &database.User{Email: email, Name: name, Password: password}
+	if err := app.models.Users.Insert(user); err != nil {
+		return nil, err
+	}
+
+	if err := app.models.Users.MarkVerified(user.Id); err != nil {
+		return nil, err
+	}
+	user.Verified = true
+
+	return user, nil
+}
+
 type updateUserRequest struct {
-	Email    string `json:"email" binding:"omitempty,email"`
-	Name     string `json:"name" binding:"omitempty,min=2"`
-	Password string `json:"password" binding:"omitempty,min=8"`
+	Email           string `json:"email" binding:"omitempty,email"`
+	Name            string `json:"name" binding:"omitempty,min=2"`
+	Password        string `json:"password"`
+	CurrentPassword string `json:"current_password"`
+}
+
+type updateUserResponse struct {
+	User         database.User `json:"user"`
+	PendingEmail string        `json:"pending_email,omitempty"`
 }
 
 // updateUser updates user information
 //
 // @Summary Update user information
-// @Description Update user email, name, and password
+// @Description Name changes apply immediately. Password changes require current_password, log out every other session and revoke every API key.
+// @Description Email changes stay pending until the link sent to the new address is confirmed.
 // @Tags user
 // @Accept json
 // @Produce json
 // @Param user body updateUserRequest true "User update data"
-// @Success 200 {object} database.User
+// @Success 200 {object} updateUserResponse
 // @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Failure 500 {object} map[string]string
+// @Security BearerAuth
 // @Router /auth/user [put]
 func (app *application) updateUser(c *gin.Context) {
 	var updateReq updateUserRequest
@@ -153,13 +286,92 @@ func (app *application) updateUser(c *gin.Context) {
 		return
 	}
 
-	userId := c.MustGet("user_id").(int)
+	user := app.GetUserFromContext(c)
+
+	// 變更密碼必須先確認目前的密碼
+	var hashedPassword string
+	if updateReq.Password != "" {
+		if updateReq.CurrentPassword == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "current_password is required to change password"})
+			return
+		}
+
+		if !app.verifyPassword(user, updateReq.CurrentPassword) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Current password is incorrect"})
+			return
+		}
+
+		name := user.Name
+		if updateReq.Name != "" {
+			name = updateReq.Name
+		}
+
+		if !app.checkPasswordPolicy(c, updateReq.Password, user.Email, name) {
+			return
+		}
+
+		hash, err := app.passwords.Hash(updateReq.Password)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+			return
+		}
+		hashedPassword = hash
+	}
+
+	// Email 變更需先確認新信箱，因此在更新其他欄位前先檢查是否可用
+	pendingEmail := ""
+	if updateReq.Email != "" && updateReq.Email != user.Email {
+		existingUser, err := app.models.Users.GetByEmail(updateReq.Email)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+			return
+		}
+
+		if existingUser != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "Email is already in use"})
+			return
+		}
 
-	updatedUser, err := app.models.Users.Update(userId, updateReq.Email, updateReq.Name)
+		pendingEmail = updateReq.Email
+	}
+
+	updatedUser, err := app.models.Users.Update(user.Id, updateReq.Name, hashedPassword)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to update user"})
 		return
 	}
 
-	c.JSON(http.StatusOK, updatedUser)
+	// 密碼變更後，所有尚未使用的重設 token 一律作廢，其他裝置也一併登出，API key 同樣撤銷
+	if hashedPassword != "" {
+		if err := app.models.PasswordResets.DeleteAllForUser(user.Id); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to update user"})
+			return
+		}
+
+		if err := app.models.Sessions.RevokeAllForUser(user.Id, app.GetClaimsFromContext(c).SessionId); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to update user"})
+			return
+		}
+
+		if err := app.models.APIKeys.DeleteAllForUser(user.Id); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to update user"})
+			return
+		}
+	}
+
+	if pendingEmail != "" {
+		token, err := app.models.EmailChanges.Create(user.Id, pendingEmail, emailChangeTTL)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to update user"})
+			return
+		}
+
+		app.sendEmail(pendingEmail, "email_change.tmpl", map[string]any{
+			"Name":       updatedUser.Name,
+			"NewEmail":   pendingEmail,
+			"ConfirmURL": app.appURL("/api/v1/auth/email/confirm", url.Values{"token": {token}}),
+		})
+	}
+
+	c.JSON(http.StatusOK, updateUserResponse{User: *updatedUser, PendingEmail: pendingEmail})
 }
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"errors"
+	"event-api-app/internal/database"
+	"event-api-app/internal/env"
+	"event-api-app/internal/ldapauth"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// errAuthUnavailable is returned by authenticate when no provider accepted
+// the credentials and at least one of them could not be reached.
+var errAuthUnavailable = errors.New("authentication provider is unavailable")
+
+// Authenticator checks an email and password against one source of
+// credentials. It returns a nil user when the credentials are not valid for
+// that source, so the next provider in the chain can try them.
+type Authenticator interface {
+	Name() string
+	Authenticate(email, password string) (*database.User, error)
+}
+
+// localAuthenticator checks the password stored in the users table.
+type localAuthenticator struct {
+	app *application
+}
+
+func (a *localAuthenticator) Name() string { return "local" }
+
+func (a *localAuthenticator) Authenticate(email, password string) (*database.User, error) {
+	user, err := a.app.models.Users.GetByEmail(email)
+	if err != nil || user == nil {
+		return nil, err
+	}
+
+	if !a.app.verifyPassword(user, password) {
+		return nil, nil
+	}
+
+	return user, nil
+}
+
+// ldapGroupRole maps members of an LDAP group to a role.
+type ldapGroupRole struct {
+	groupDN string
+	role    string
+}
+
+// ldapAuthenticator checks the password against an LDAP or Active Directory
+// server. Users seen for the first time are created on the fly, and their
+// role follows their directory groups on every login.
+type ldapAuthenticator struct {
+	app         *application
+	client      *ldapauth.Client
+	groupRoles  []ldapGroupRole
+	defaultRole string
+}
+
+func (a *ldapAuthenticator) Name() string { return "ldap" }
+
+func (a *ldapAuthenticator) Authenticate(email, password string) (*database.User, error) {
+	entry, err := a.client.Authenticate(email, password)
+	if errors.Is(err, ldapauth.ErrInvalidCredentials) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.Email == "" {
+		entry.Email = email
+	}
+
+	user, err := a.app.models.Users.GetByEmail(entry.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		user, err = a.app.provisionUser(entry.Email, entry.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if role, managed := a.roleFor(entry); managed {
+		// 沒有符合的群組時改回最低權限的預設角色，被移出管理群組的用戶不會保留原本的角色
+		if role == "" {
+			if role, err = a.app.models.Roles.DefaultName(); err != nil {
+				return nil, err
+			}
+		}
+
+		if role != user.Role {
+			if err := a.app.models.Users.SetRole(user.Id, role); err != nil {
+				return nil, fmt.Errorf("set role %q for user %d: %w", role, user.Id, err)
+			}
+			user.Role = role
+		}
+	}
+
+	return user, nil
+}
+
+// roleFor returns the role of the first mapping whose group the entry belongs
+// to, or the default role; an empty role means the roles table's default.
+// managed is false when neither group mappings nor a default role are
+// configured, in which case the directory does not decide roles.
+func (a *ldapAuthenticator) roleFor(entry *ldapauth.Entry) (role string, managed bool) {
+	if len(a.groupRoles) == 0 && a.defaultRole == "" {
+		return "", false
+	}
+
+	for _, mapping := range a.groupRoles {
+		if entry.InGroup(mapping.groupDN) {
+			return mapping.role, true
+		}
+	}
+	return a.defaultRole, true
+}
+
+// newAuthenticators 依照 AUTH_PROVIDERS（以逗號分隔，依序嘗試）建立登入驗證鏈，預設只有 local
+func newAuthenticators(app *application) ([]Authenticator, error) {
+	var authenticators []Authenticator
+
+	for _, name := range strings.Split(env.GetEnvString("AUTH_PROVIDERS", "local"), ",") {
+		switch name = strings.TrimSpace(name); name {
+		case "":
+			continue
+		case "local":
+			authenticators = append(authenticators, &localAuthenticator{app: app})
+		case "ldap":
+			authenticator, err := newLDAPAuthenticator(app)
+			if err != nil {
+				return nil, err
+			}
+			authenticators = append(authenticators, authenticator)
+		default:
+			return nil, fmt.Errorf("unknown authentication provider %q", name)
+		}
+	}
+
+	if len(authenticators) == 0 {
+		return nil, errors.New("AUTH_PROVIDERS must name at least one provider")
+	}
+
+	return authenticators, nil
+}
+
+// newLDAPAuthenticator 讀取 LDAP_* 設定。LDAP_GROUP_ROLES 的格式為
+// "role=groupDN;role=groupDN"，依序比對，第一個符合的群組決定角色
+func newLDAPAuthenticator(app *application) (*ldapAuthenticator, error) {
+	client, err := ldapauth.New(ldapauth.Config{
+		URL:                env.GetEnvString("LDAP_URL", ""),
+		BindDN:             env.GetEnvString("LDAP_BIND_DN", ""),
+		BindPassword:       env.GetEnvString("LDAP_BIND_PASSWORD", ""),
+		BaseDN:             env.GetEnvString("LDAP_BASE_DN", ""),
+		UserFilter:         env.GetEnvString("LDAP_USER_FILTER", ""),
+		EmailAttribute:     env.GetEnvString("LDAP_EMAIL_ATTRIBUTE", ""),
+		NameAttribute:      env.GetEnvString("LDAP_NAME_ATTRIBUTE", ""),
+		GroupAttribute:     env.GetEnvString("LDAP_GROUP_ATTRIBUTE", ""),
+		StartTLS:           env.GetEnvBool("LDAP_START_TLS", false),
+		InsecureSkipVerify: env.GetEnvBool("LDAP_INSECURE_SKIP_VERIFY", false),
+		Timeout:            time.Duration(env.GetEnvInt("LDAP_TIMEOUT_SECONDS", 10)) * time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	authenticator := &ldapAuthenticator{
+		app:         app,
+		client:      client,
+		defaultRole: env.GetEnvString("LDAP_DEFAULT_ROLE", ""),
+	}
+
+	for _, pair := range strings.Split(env.GetEnvString("LDAP_GROUP_ROLES", ""), ";") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		role, groupDN, found := strings.Cut(pair, "=")
+		if !found || strings.TrimSpace(role) == "" || strings.TrimSpace(groupDN) == "" {
+			return nil, fmt.Errorf("invalid LDAP_GROUP_ROLES entry %q, expected role=groupDN", pair)
+		}
+
+		authenticator.groupRoles = append(authenticator.groupRoles, ldapGroupRole{
+			groupDN: strings.TrimSpace(groupDN),
+			role:    strings.TrimSpace(role),
+		})
+	}
+
+	return authenticator, nil
+}
+
+// authenticate tries each provider in order and returns the first user whose
+// credentials are accepted, or nil. Provider errors are logged and skipped;
+// errAuthUnavailable is returned only if no provider accepted the credentials.
+func (app *application) authenticate(email, password string) (*database.User, error) {
+	var failed bool
+
+	for _, authenticator := range app.authenticators {
+		user, err := authenticator.Authenticate(email, password)
+		if err != nil {
+			log.Printf("%s authentication for %s: %v", authenticator.Name(), email, err)
+			failed = true
+			continue
+		}
+		if user != nil {
+			return user, nil
+		}
+	}
+
+	if failed {
+		return nil, errAuthUnavailable
+	}
+	return nil, nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"event-api-app/internal/ldapauth"
+	"testing"
+)
+
+func TestLDAPRoleFor(t *testing.T) {
+	const (
+		adminsDN = "cn=event-admins,ou=groups,dc=example,dc=com"
+		staffDN  = "cn=staff,ou=groups,dc=example,dc=com"
+	)
+
+	mappings := []ldapGroupRole{
+		{groupDN: adminsDN, role: "admin"},
+		{groupDN: staffDN, role: "moderator"},
+	}
+
+	tests := []struct {
+		name        string
+		groupRoles  []ldapGroupRole
+		defaultRole string
+		groups      []string
+		wantRole    string
+		wantManaged bool
+	}{
+		{"first matching group wins", mappings, "", []string{staffDN, adminsDN}, "admin", true},
+		{"group DNs compare case-insensitively", mappings, "", []string{"CN=Staff,OU=Groups,DC=example,DC=com"}, "moderator", true},
+		{"no match uses LDAP_DEFAULT_ROLE", mappings, "user", []string{"cn=other,dc=example,dc=com"}, "user", true},
+		{"no match without LDAP_DEFAULT_ROLE resets to the default role", mappings, "", nil, "", true},
+		{"default role only", nil, "user", []string{adminsDN}, "user", true},
+		{"roles not managed", nil, "", []string{adminsDN}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &ldapAuthenticator{groupRoles: tt.groupRoles, defaultRole: tt.defaultRole}
+
+			role, managed := a.roleFor(&ldapauth.Entry{Groups: tt.groups})
+			if role != tt.wantRole || managed != tt.wantManaged {
+				t.Fatalf("got (%q, %v), want (%q, %v)", role, managed, tt.wantRole, tt.wantManaged)
+			}
+		})
+	}
+}
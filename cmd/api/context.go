@@ -20,3 +20,54 @@ func (app *application) GetUserFromContext(c *gin.Context) *database.User {
 
 	return user
 }
+
+func (app *application) GetClaimsFromContext(c *gin.Context) *tokenClaims {
+	contextClaims, exists := c.Get("claims")
+	if !exists {
+		return nil
+	}
+
+	claims, ok := contextClaims.(*tokenClaims)
+	if !ok {
+		return nil
+	}
+
+	return claims
+}
+
+func (app *application) GetAPIKeyFromContext(c *gin.Context) *database.APIKey {
+	contextKey, exists := c.Get("api_key")
+	if !exists {
+		return nil
+	}
+
+	key, ok := contextKey.(*database.APIKey)
+	if !ok {
+		return nil
+	}
+
+	return key
+}
+
+func (app *application) GetOrganizationFromContext(c *gin.Context) *database.Organization {
+	contextOrg, exists := c.Get("organization")
+	if !exists {
+		return &database.Organization{}
+	}
+
+	org, ok := contextOrg.(*database.Organization)
+	if !ok {
+		return &database.Organization{}
+	}
+
+	return org
+}
+
+// GetTenantFromContext scopes event queries to the request's organization,
+// as seen by the current user (anonymous if there is none).
+func (app *application) GetTenantFromContext(c *gin.Context) database.Tenant {
+	return database.Tenant{
+		OrganizationId: app.GetOrganizationFromContext(c).Id,
+		ViewerId:       app.GetUserFromContext(c).Id,
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"event-api-app/internal/database"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type confirmEmailChangeRequest struct {
+	Token string `json:"token" form:"token" binding:"required"`
+}
+
+// confirmEmailChange applies a pending email change
+//
+// @Summary Confirm email change
+// @Description Consume the token sent to the new address and switch the account to it
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param token query string false "Confirmation token (GET)"
+// @Param request body confirmEmailChangeRequest false "Confirmation token (POST)"
+// @Success 200 {object} database.User
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/email/confirm [get]
+// @Router /auth/email/confirm [post]
+func (app *application) confirmEmailChange(c *gin.Context) {
+	var req confirmEmailChangeRequest
+
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := app.models.EmailChanges.Confirm(req.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrInvalidToken):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired confirmation token"})
+		case errors.Is(err, database.ErrDuplicateEmail):
+			c.JSON(http.StatusConflict, gin.H{"error": "Email is already in use"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change email"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
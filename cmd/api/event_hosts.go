@@ -0,0 +1,321 @@
+package main
+
+import (
+	"event-api-app/internal/database"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Actions on a single event that the owner, hosts and organization admins
+// may perform regardless of their global role.
+const (
+	eventActionView      = "view"
+	eventActionEdit      = "edit"
+	eventActionDelete    = "delete"
+	eventActionAttendees = "attendees"
+	eventActionHosts     = "hosts"
+	eventActionTransfer  = "transfer"
+)
+
+// eventRoleOwner is the event role of the event's owner.
+const eventRoleOwner = "owner"
+
+// eventRoleActions lists what each event role allows. Owners edit and delete
+// their events through the events.update.own and events.delete.own
+// permissions instead, so roles can still take that away.
+var eventRoleActions = map[string][]string{
+	eventRoleOwner:           {eventActionView, eventActionAttendees, eventActionHosts, eventActionTransfer},
+	database.HostRoleCoHost:  {eventActionView, eventActionEdit, eventActionAttendees},
+	database.HostRoleCheckIn: {eventActionView, eventActionAttendees},
+	database.HostRoleViewer:  {eventActionView},
+}
+
+type inviteEventHostRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=co_host check_in viewer"`
+}
+
+type transferEventRequest struct {
+	UserId int `json:"user_id" binding:"required"`
+}
+
+// eventRole returns the user's role on the event: owner, a host role, or ""
+// if the user has none.
+func (app *application) eventRole(userId int, event *database.Event) (string, error) {
+	if userId == 0 {
+		return "", nil
+	}
+
+	if event.OwnerId == userId {
+		return eventRoleOwner, nil
+	}
+
+	return app.models.EventHosts.GetRole(event.Id, userId)
+}
+
+// canOnEvent reports whether the user may perform action on the event, as its
+// owner or a host whose role allows it, or as an owner or admin of the
+// event's organization.
+func (app *application) canOnEvent(user *database.User, event *database.Event, action string) (bool, error) {
+	role, err := app.eventRole(user.Id, event)
+	if err != nil {
+		return false, err
+	}
+
+	if slices.Contains(eventRoleActions[role], action) {
+		return true, nil
+	}
+
+	return app.isOrganizationAdmin(event.OrganizationId, user.Id)
+}
+
+// canManageEvent reports whether the user may change the event through the
+// global policy (any, or own for the event's owner) or through canOnEvent.
+func (app *application) canManageEvent(user *database.User, event *database.Event, permission, action string) (bool, error) {
+	allowed, err := app.policy.CanOnOwned(user.Role, user.Id, event.OwnerId, permission)
+	if err != nil || allowed {
+		return allowed, err
+	}
+
+	return app.canOnEvent(user, event, action)
+}
+
+// getEventForAction loads the event named by the :id path parameter and
+// checks that the current user may perform action on it. It writes an error
+// response and returns nil otherwise.
+func (app *application) getEventForAction(c *gin.Context, action string) *database.Event {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return nil
+	}
+
+	event, err := app.models.Events.Get(app.GetTenantFromContext(c), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve event"})
+		return nil
+	}
+
+	if event == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return nil
+	}
+
+	allowed, err := app.canOnEvent(app.GetUserFromContext(c), event, action)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check permissions"})
+		return nil
+	}
+
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to do this for this event"})
+		return nil
+	}
+
+	return event
+}
+
+// getEventHosts lists an event's hosts
+//
+// @Summary List event hosts
+// @Description List the co-hosts, check-in staff and viewers of an event (visible to the owner, hosts and organization admins)
+// @Tags events
+// @Produce json
+// @Param X-Organization header string false "Organization slug"
+// @Param id path int true "Event ID"
+// @Success 200 {array} database.EventHost
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /events/{id}/hosts [get]
+func (app *application) getEventHosts(c *gin.Context) {
+	event := app.getEventForAction(c, eventActionView)
+	if event == nil {
+		return
+	}
+
+	app.respondEventHosts(c, http.StatusOK, event.Id)
+}
+
+// inviteEventHost adds a host to an event
+//
+// @Summary Invite event host
+// @Description Give an existing user a role on the event (owner and organization admins). Inviting an existing host changes their role.
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param X-Organization header string false "Organization slug"
+// @Param id path int true "Event ID"
+// @Param request body inviteEventHostRequest true "User email and role: co_host, check_in or viewer"
+// @Success 201 {array} database.EventHost
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /events/{id}/hosts [post]
+func (app *application) inviteEventHost(c *gin.Context) {
+	event := app.getEventForAction(c, eventActionHosts)
+	if event == nil {
+		return
+	}
+
+	var req inviteEventHostRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	host, err := app.models.Users.GetByEmail(req.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
+		return
+	}
+
+	if host == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if host.Id == event.OwnerId {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The owner cannot also be a host"})
+		return
+	}
+
+	user := app.GetUserFromContext(c)
+
+	if err := app.models.EventHosts.Set(event.Id, host.Id, req.Role, user.Id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add host"})
+		return
+	}
+
+	app.respondEventHosts(c, http.StatusCreated, event.Id)
+}
+
+// removeEventHost removes a host from an event
+//
+// @Summary Remove event host
+// @Description Remove a host (owner and organization admins), or step down by removing yourself
+// @Tags events
+// @Param X-Organization header string false "Organization slug"
+// @Param id path int true "Event ID"
+// @Param userId path int true "User ID"
+// @Success 204 "Host removed"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /events/{id}/hosts/{userId} [delete]
+func (app *application) removeEventHost(c *gin.Context) {
+	userId, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	// 協辦人可以自行退出，移除他人需要管理協辦人的權限
+	action := eventActionHosts
+	if userId == app.GetUserFromContext(c).Id {
+		action = eventActionView
+	}
+
+	event := app.getEventForAction(c, action)
+	if event == nil {
+		return
+	}
+
+	removed, err := app.models.EventHosts.Remove(event.Id, userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove host"})
+		return
+	}
+
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Host not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// transferEventOwnership hands an event to another user
+//
+// @Summary Transfer event ownership
+// @Description Make another member of the event's organization the owner (owner and organization admins). The previous owner stays on as a co-host.
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param X-Organization header string false "Organization slug"
+// @Param id path int true "Event ID"
+// @Param request body transferEventRequest true "New owner"
+// @Success 200 {object} database.Event
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /events/{id}/transfer [post]
+func (app *application) transferEventOwnership(c *gin.Context) {
+	event := app.getEventForAction(c, eventActionTransfer)
+	if event == nil {
+		return
+	}
+
+	var req transferEventRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.UserId == event.OwnerId {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User already owns this event"})
+		return
+	}
+
+	// 新的主辦人必須是活動所屬組織的成員
+	role, err := app.models.Organizations.GetMemberRole(event.OrganizationId, req.UserId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
+		return
+	}
+
+	if role == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The new owner must be a member of the event's organization"})
+		return
+	}
+
+	if err := app.models.EventHosts.TransferOwnership(event.Id, req.UserId); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer event"})
+		return
+	}
+
+	event, err = app.models.Events.Get(app.GetTenantFromContext(c), event.Id)
+	if err != nil || event == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve event"})
+		return
+	}
+
+	c.JSON(http.StatusOK, event)
+}
+
+func (app *application) respondEventHosts(c *gin.Context, status, eventId int) {
+	hosts, err := app.models.EventHosts.GetByEvent(eventId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve hosts"})
+		return
+	}
+
+	c.JSON(status, hosts)
+}
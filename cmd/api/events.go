@@ -11,14 +11,17 @@ import (
 // createEvent creates a new event
 //
 // @Summary Create a new event
-// @Description Create a new event with the provided information
+// @Description Create a new event in the organization named by X-Organization (the default organization if omitted). The caller must be a member.
 // @Tags events
 // @Accept json
 // @Produce json
+// @Param X-Organization header string false "Organization slug"
 // @Param event body database.Event true "Event object to be created"
 // @Success 201 {object} database.Event
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Security BearerAuth
 // @Router /events [post]
@@ -31,9 +34,23 @@ func (app *application) createEvent(c *gin.Context) {
 	}
 
 	user := app.GetUserFromContext(c)
+	org := app.GetOrganizationFromContext(c)
+
+	role, err := app.models.Organizations.GetMemberRole(org.Id, user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check permissions"})
+		return
+	}
+
+	if role == "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not a member of this organization"})
+		return
+	}
+
 	event.OwnerId = user.Id
+	event.OrganizationId = org.Id
 
-	err := app.models.Events.Insert(&event)
+	err = app.models.Events.Insert(&event)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create event"})
@@ -46,14 +63,15 @@ func (app *application) createEvent(c *gin.Context) {
 // getEvents return all events
 //
 // @Summary Get all events
-// @Description Get a list of all events
+// @Description Get a list of the organization's events. Private events are only listed for members.
 // @Tags events
 // @Accept json
 // @Produce json
+// @Param X-Organization header string false "Organization slug"
 // @Success 200 {object} []database.Event
 // @Router /api/v1/events [get]
 func (app *application) getAllEvents(c *gin.Context) {
-	events, err := app.models.Events.GetAll()
+	events, err := app.models.Events.GetAll(app.GetTenantFromContext(c))
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve events"})
@@ -66,10 +84,11 @@ func (app *application) getAllEvents(c *gin.Context) {
 // getEvent retrieves a single event by ID
 //
 // @Summary Get an event
-// @Description Retrieve a single event by its ID
+// @Description Retrieve a single event of the organization by its ID
 // @Tags events
 // @Accept json
 // @Produce json
+// @Param X-Organization header string false "Organization slug"
 // @Param id path int true "Event ID"
 // @Success 200 {object} database.Event
 // @Failure 400 {object} map[string]string
@@ -83,7 +102,7 @@ func (app *application) getEvent(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
 	}
 
-	event, err := app.models.Events.Get(id)
+	event, err := app.models.Events.Get(app.GetTenantFromContext(c), id)
 
 	if event == nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
@@ -107,6 +126,7 @@ func (app *application) getEvent(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Event ID"
 // @Param event body database.Event true "Updated event object"
+// @Param X-Organization header string false "Organization slug"
 // @Success 200 {object} database.Event
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
@@ -124,7 +144,7 @@ func (app *application) updateEvent(c *gin.Context) {
 	}
 
 	user := app.GetUserFromContext(c)
-	existingEvent, err := app.models.Events.Get(id)
+	existingEvent, err := app.models.Events.Get(app.GetTenantFromContext(c), id)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve event"})
@@ -136,8 +156,14 @@ func (app *application) updateEvent(c *gin.Context) {
 		return
 	}
 
-	// Admin can update any event, regular users can only update their own events
-	if user.Role != "admin" && existingEvent.OwnerId != user.Id {
+	// events.update.any 可更新任何活動，events.update.own 僅能更新自己的活動；協辦人與組織管理員可更新該活動
+	allowed, err := app.canManageEvent(user, existingEvent, "events.update", eventActionEdit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check permissions"})
+		return
+	}
+
+	if !allowed {
 		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to update this event"})
 		return
 	}
@@ -150,8 +176,10 @@ func (app *application) updateEvent(c *gin.Context) {
 	}
 
 	updatedEvent.Id = id
+	updatedEvent.OwnerId = existingEvent.OwnerId
+	updatedEvent.OrganizationId = existingEvent.OrganizationId
 
-	if err := app.models.Events.Update(updatedEvent); err != nil {
+	if err := app.models.Events.Update(app.GetTenantFromContext(c), updatedEvent); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event"})
 		return
 	}
@@ -165,6 +193,7 @@ func (app *application) updateEvent(c *gin.Context) {
 // @Description Delete an existing event by ID
 // @Tags events
 // @Param id path int true "Event ID"
+// @Param X-Organization header string false "Organization slug"
 // @Success 204 "Event successfully deleted"
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
@@ -182,7 +211,7 @@ func (app *application) deleteEvent(c *gin.Context) {
 	}
 
 	user := app.GetUserFromContext(c)
-	existingEvent, err := app.models.Events.Get(id)
+	existingEvent, err := app.models.Events.Get(app.GetTenantFromContext(c), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve event"})
 		return
@@ -193,13 +222,19 @@ func (app *application) deleteEvent(c *gin.Context) {
 		return
 	}
 
-	// Admin can delete any event, regular users can only delete their own events
-	if user.Role != "admin" && existingEvent.OwnerId != user.Id {
+	// events.delete.any 可刪除任何活動，events.delete.own 僅能刪除自己的活動；組織管理員可刪除組織內的活動
+	allowed, err := app.canManageEvent(user, existingEvent, "events.delete", eventActionDelete)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check permissions"})
+		return
+	}
+
+	if !allowed {
 		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to delete this event"})
 		return
 	}
 
-	if err := app.models.Events.Delete(id); err != nil {
+	if err := app.models.Events.Delete(app.GetTenantFromContext(c), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete event"})
 		return
 	}
@@ -210,14 +245,16 @@ func (app *application) deleteEvent(c *gin.Context) {
 // addAttendeeToEvent adds an attendee to an event
 //
 // @Summary Add attendee to event
-// @Description Add a user as an attendee to a specific event
+// @Description Add a user as an attendee to a specific event. Users can add themselves; adding others requires managing the event's attendees (owner, co-host, check-in staff or organization admin) or the attendees.add.any permission.
 // @Tags attendees
 // @Accept json
 // @Produce json
 // @Param id path int true "Event ID"
 // @Param userId path int true "User ID"
+// @Param X-Organization header string false "Organization slug"
 // @Success 201 {object} database.Attendee
 // @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Failure 409 {object} map[string]string
 // @Failure 500 {object} map[string]string
@@ -235,7 +272,7 @@ func (app *application) addAttendeeToEvent(c *gin.Context) {
 		return
 	}
 
-	event, err := app.models.Events.Get(eventId)
+	event, err := app.models.Events.Get(app.GetTenantFromContext(c), eventId)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve event"})
 		return
@@ -246,6 +283,26 @@ func (app *application) addAttendeeToEvent(c *gin.Context) {
 		return
 	}
 
+	// 任何人都能報名自己；替他人報名需要活動的參加者管理權限或 attendees.add.any
+	user := app.GetUserFromContext(c)
+	allowed := userId == user.Id
+
+	if !allowed {
+		allowed, err = app.canOnEvent(user, event, eventActionAttendees)
+		if err == nil && !allowed {
+			allowed, err = app.policy.Can(user.Role, "attendees.add.any")
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check permissions"})
+			return
+		}
+	}
+
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to add this attendee"})
+		return
+	}
+
 	// Check if the user exists
 	userToAdd, err := app.models.Users.Get(userId)
 	if err != nil {
@@ -259,7 +316,7 @@ func (app *application) addAttendeeToEvent(c *gin.Context) {
 	}
 
 	// Check if the attendee already exists
-	existingAttendee, err := app.models.Attendees.GetByEventAndAttendee(eventId, userId)
+	existingAttendee, err := app.models.Attendees.GetByEventAndAttendee(app.GetTenantFromContext(c), eventId, userId)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve attendee"})
@@ -276,12 +333,17 @@ func (app *application) addAttendeeToEvent(c *gin.Context) {
 		UserId:  userToAdd.Id,
 	}
 
-	_, err = app.models.Attendees.Insert(&attendee)
+	inserted, err := app.models.Attendees.Insert(app.GetTenantFromContext(c), &attendee)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add attendee to event"})
 		return
 	}
 
+	if inserted == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
 	c.JSON(http.StatusCreated, attendee)
 }
 
@@ -293,6 +355,7 @@ func (app *application) addAttendeeToEvent(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "Event ID"
+// @Param X-Organization header string false "Organization slug"
 // @Success 200 {array} database.User
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
@@ -306,7 +369,7 @@ func (app *application) getAttendeesForEvent(c *gin.Context) {
 		return
 	}
 
-	users, err := app.models.Attendees.GetAttendeesByEvent(id)
+	users, err := app.models.Attendees.GetAttendeesByEvent(app.GetTenantFromContext(c), id)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve attendees for event"})
@@ -325,6 +388,7 @@ func (app *application) getAttendeesForEvent(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Event ID"
 // @Param userId path int true "User ID"
+// @Param X-Organization header string false "Organization slug"
 // @Success 204 "Attendee successfully removed"
 // @Failure 400 {object} map[string]string
 // @Failure 403 {object} map[string]string
@@ -344,7 +408,7 @@ func (app *application) deleteAttendeeFromEvent(c *gin.Context) {
 		return
 	}
 
-	event, err := app.models.Events.Get(id)
+	event, err := app.models.Events.Get(app.GetTenantFromContext(c), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve event"})
 		return
@@ -357,13 +421,27 @@ func (app *application) deleteAttendeeFromEvent(c *gin.Context) {
 
 	user := app.GetUserFromContext(c)
 
-	// Allow admin to remove any user or allow users to remove themselves
-	if user.Role != "admin" && user.Id != userId {
+	// attendees.remove.any 可移除任何參加者，attendees.remove.own 僅能移除自己；活動主辦人、協辦人、報到人員與組織管理員可移除該活動的參加者
+	allowed, err := app.policy.CanOnOwned(user.Role, user.Id, userId, "attendees.remove")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check permissions"})
+		return
+	}
+
+	if !allowed {
+		allowed, err = app.canOnEvent(user, event, eventActionAttendees)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check permissions"})
+			return
+		}
+	}
+
+	if !allowed {
 		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to remove this attendee"})
 		return
 	}
 
-	err = app.models.Attendees.Delete(userId, id)
+	err = app.models.Attendees.Delete(app.GetTenantFromContext(c), userId, id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete attendee from event"})
 		return
@@ -380,6 +458,7 @@ func (app *application) deleteAttendeeFromEvent(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param userId path int true "User ID"
+// @Param X-Organization header string false "Organization slug"
 // @Success 200 {array} database.Event
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
@@ -393,7 +472,7 @@ func (app *application) getEventsByAttendee(c *gin.Context) {
 		return
 	}
 
-	events, err := app.models.Attendees.GetEventsByAttendee(id)
+	events, err := app.models.Attendees.GetEventsByAttendee(app.GetTenantFromContext(c), id)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve events for attendee"})
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"event-api-app/internal/database"
+	"event-api-app/internal/env"
+	"event-api-app/internal/passhash"
+	"event-api-app/internal/pwpolicy"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newPasswordHasher 依照 PASSWORD_ARGON2_* 設定 Argon2id 參數，未設定時使用預設值
+func newPasswordHasher() *passhash.Hasher {
+	params := passhash.DefaultParams
+	params.Memory = uint32(env.GetEnvInt("PASSWORD_ARGON2_MEMORY_KIB", int(params.Memory)))
+	params.Iterations = uint32(env.GetEnvInt("PASSWORD_ARGON2_ITERATIONS", int(params.Iterations)))
+	params.Parallelism = uint8(env.GetEnvInt("PASSWORD_ARGON2_PARALLELISM", int(params.Parallelism)))
+
+	return passhash.New(params)
+}
+
+// verifyPassword checks password against the user's stored hash. Hashes made
+// with bcrypt or outdated Argon2id parameters are upgraded once the password
+// is known to be correct.
+func (app *application) verifyPassword(user *database.User, password string) bool {
+	match, needsRehash, err := app.passwords.Verify(password, user.Password)
+	if err != nil {
+		log.Printf("verify password for user %d: %v", user.Id, err)
+		return false
+	}
+
+	if match && needsRehash {
+		hash, err := app.passwords.Hash(password)
+		if err == nil {
+			_, err = app.models.Users.Update(user.Id, "", hash)
+		}
+		if err != nil {
+			log.Printf("rehash password for user %d: %v", user.Id, err)
+		} else {
+			user.Password = hash
+		}
+	}
+
+	return match
+}
+
+// newPasswordPolicy 建立密碼政策：PASSWORD_MIN_LENGTH/PASSWORD_MAX_LENGTH 限制長度，
+// PASSWORD_BANNED_FILE 追加禁用密碼清單，PASSWORD_BREACHED_FILE 載入外洩密碼的 SHA-1 清單（離線比對）
+func newPasswordPolicy() (*pwpolicy.Policy, error) {
+	policy := pwpolicy.New(env.GetEnvInt("PASSWORD_MIN_LENGTH", 8), env.GetEnvInt("PASSWORD_MAX_LENGTH", 128))
+
+	if path := env.GetEnvString("PASSWORD_BANNED_FILE", ""); path != "" {
+		if err := policy.LoadBannedFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if path := env.GetEnvString("PASSWORD_BREACHED_FILE", ""); path != "" {
+		breached, err := pwpolicy.LoadBreachedFile(path)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Loaded %d breached password hashes", breached.Len())
+		policy.SetBreached(breached)
+	}
+
+	return policy, nil
+}
+
+// checkPasswordPolicy writes a 400 response listing the violations and returns
+// false when password is not acceptable for the account with email and name.
+func (app *application) checkPasswordPolicy(c *gin.Context, password, email, name string) bool {
+	err := app.passwordPolicy.Check(password, email, name)
+	if err == nil {
+		return true
+	}
+
+	var violations pwpolicy.Violations
+	if errors.As(err, &violations) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Password does not meet the policy", "violations": violations})
+		return false
+	}
+
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+	return false
+}
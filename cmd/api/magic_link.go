@@ -0,0 +1,178 @@
+package main
+
+import (
+	"errors"
+	"event-api-app/internal/database"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const magicLinkTTL = 15 * time.Minute
+
+type magicLinkRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type magicLinkLoginRequest struct {
+	Token string `json:"token" form:"token" binding:"required"`
+}
+
+// magicLinkConfirmPage asks the user to confirm the sign-in. Opening the link
+// does not use it up, so mail scanners and link previews cannot spend it.
+var magicLinkConfirmPage = template.Must(template.New("magic_link").Parse(`<!doctype html>
+<html>
+<head>
+    <meta name="viewport" content="width=device-width" />
+    <meta http-equiv="Content-Type" content="text/html; charset=UTF-8" />
+    <title>Sign in</title>
+</head>
+<body>
+    <form method="post" action="{{.Action}}">
+        <input type="hidden" name="token" value="{{.Token}}" />
+        <p>Continue to sign in to your account.</p>
+        <button type="submit">Sign in</button>
+    </form>
+</body>
+</html>
+`))
+
+// requestMagicLink emails a one-time sign-in link
+//
+// @Summary Request a magic sign-in link
+// @Description Email a single-use sign-in link to the account. The response does not reveal whether the email exists.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body magicLinkRequest true "Account email"
+// @Success 202 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/magic-link [post]
+func (app *application) requestMagicLink(c *gin.Context) {
+	var req magicLinkRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := app.models.Users.GetByEmail(req.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	// 不論帳號是否存在都回傳相同訊息，避免洩漏註冊狀態
+	if user != nil && !user.IsDisabled() {
+		token, err := app.models.Tokens.Issue(database.TokenPurposeMagicLink, user.Id, user.Email, magicLinkTTL)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+			return
+		}
+
+		app.sendEmail(user.Email, "magic_link.tmpl", map[string]any{
+			"Name":             user.Name,
+			"LoginURL":         app.appURL("/api/v1/auth/magic-link/verify", url.Values{"token": {token}}),
+			"ExpiresInMinutes": int(magicLinkTTL.Minutes()),
+		})
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If an account with that email exists, a sign-in link has been sent"})
+}
+
+// confirmMagicLink shows the page that the emailed link opens
+//
+// @Summary Confirm a magic sign-in link
+// @Description Show a page that signs in by posting the token to /auth/magic-link/verify. Opening the link does not consume the token.
+// @Tags authentication
+// @Produce html
+// @Param token query string true "Magic link token"
+// @Success 200 {string} string "Confirmation page"
+// @Failure 400 {object} map[string]string
+// @Router /auth/magic-link/verify [get]
+func (app *application) confirmMagicLink(c *gin.Context) {
+	var req magicLinkLoginRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// token 在網址中，避免被快取或透過 Referer 外洩
+	c.Header("Cache-Control", "no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	err := magicLinkConfirmPage.Execute(c.Writer, map[string]string{
+		"Action": "/api/v1/auth/magic-link/verify",
+		"Token":  req.Token,
+	})
+	if err != nil {
+		log.Printf("render magic link page: %v", err)
+	}
+}
+
+// loginMagicLink signs the user in with a magic link
+//
+// @Summary Sign in with a magic link
+// @Description Consume a magic link token. Responds like /auth/login and marks the email as verified. Each link works once.
+// @Tags authentication
+// @Accept json,x-www-form-urlencoded
+// @Produce json
+// @Param request body magicLinkLoginRequest true "Magic link token"
+// @Success 200 {object} loginResponse
+// @Success 202 {object} mfaChallengeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/magic-link/verify [post]
+func (app *application) loginMagicLink(c *gin.Context) {
+	var req magicLinkLoginRequest
+
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 連結僅能使用一次
+	token, err := app.models.Tokens.Consume(database.TokenPurposeMagicLink, req.Token)
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired sign-in link"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	user, err := app.models.Users.Get(token.UserId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	// 連結寄出後若已變更 email，舊信箱收到的連結不再有效
+	if user == nil || user.Email != token.Payload {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired sign-in link"})
+		return
+	}
+
+	// 能收到連結即證明擁有此信箱
+	if !user.Verified {
+		if err := app.models.Users.MarkVerified(user.Id); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+			return
+		}
+		user.Verified = true
+	}
+
+	app.completeLogin(c, user)
+}
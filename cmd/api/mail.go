@@ -0,0 +1,76 @@
+package main
+
+import (
+	"event-api-app/internal/database"
+	"event-api-app/internal/env"
+	"event-api-app/internal/mailer"
+	"fmt"
+	"log"
+	"net/url"
+)
+
+// newMailer 依照 MAILER_BACKEND 建立寄信後端（smtp 或 file）
+func newMailer() (mailer.Mailer, error) {
+	from := env.GetEnvString("MAILER_FROM", "Event API <no-reply@localhost>")
+
+	switch backend := env.GetEnvString("MAILER_BACKEND", "file"); backend {
+	case "smtp":
+		return mailer.NewSMTPMailer(
+			env.GetEnvString("SMTP_HOST", "localhost"),
+			env.GetEnvInt("SMTP_PORT", 587),
+			env.GetEnvString("SMTP_USERNAME", ""),
+			env.GetEnvString("SMTP_PASSWORD", ""),
+			from,
+		), nil
+	case "file":
+		return mailer.NewFileMailer(env.GetEnvString("MAILER_OUTBOX_DIR", "outbox"), from)
+	default:
+		return nil, fmt.Errorf("unknown MAILER_BACKEND %q", backend)
+	}
+}
+
+// sendEmail renders templateFile and delivers it in the background so that
+// slow mail servers do not hold up the request.
+func (app *application) sendEmail(recipient, templateFile string, data any) {
+	go func() {
+		defer func() {
+			if err := recover(); err != nil {
+				log.Printf("Panic while sending %s to %s: %v", templateFile, recipient, err)
+			}
+		}()
+
+		msg, err := mailer.Render(recipient, templateFile, data)
+		if err != nil {
+			log.Printf("Failed to render %s: %v", templateFile, err)
+			return
+		}
+
+		if err := app.mailer.Send(msg); err != nil {
+			log.Printf("Failed to send %s to %s: %v", templateFile, recipient, err)
+		}
+	}()
+}
+
+// appURL builds an absolute link to path on this API with the given query parameters.
+func (app *application) appURL(path string, query url.Values) string {
+	u := app.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+// sendVerificationEmail issues a verification token for the user's current
+// address, replacing any earlier one, and mails it.
+func (app *application) sendVerificationEmail(user *database.User) error {
+	token, err := app.models.Tokens.Issue(database.TokenPurposeVerify, user.Id, user.Email, verifyTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	app.sendEmail(user.Email, "user_verify.tmpl", map[string]any{
+		"Name":      user.Name,
+		"VerifyURL": app.appURL("/api/v1/auth/verify", url.Values{"token": {token}}),
+	})
+	return nil
+}
@@ -4,10 +4,19 @@ import (
 	"database/sql"
 	"event-api-app/internal/database"
 	"event-api-app/internal/env"
+	"event-api-app/internal/mailer"
+	"event-api-app/internal/passhash"
+	"event-api-app/internal/policy"
+	"event-api-app/internal/pwpolicy"
+	"event-api-app/internal/signing"
+	"event-api-app/internal/storage"
 	"log"
+	"time"
+	_ "time/tzdata"
 
 	_ "event-api-app/docs"
 
+	"github.com/go-webauthn/webauthn/webauthn"
 	_ "github.com/joho/godotenv/autoload"
 	_ "github.com/lib/pq"
 )
@@ -26,9 +35,21 @@ import (
 // @description Enter your bearer token in the format **Bearer &lt;token&gt;**
 
 type application struct {
-	port      int
-	jwtSecret string
-	models    database.Models
+	port           int
+	keys           *signing.KeySet
+	baseURL        string
+	mfaIssuer      string
+	deletionGrace  time.Duration
+	passwords      *passhash.Hasher
+	passwordPolicy *pwpolicy.Policy
+	models         database.Models
+	policy         *policy.Policy
+	authenticators []Authenticator
+	scimTokenHash  []byte
+	oidcProviders  map[string]*oidcProvider
+	webauthn       *webauthn.WebAuthn
+	mailer         mailer.Mailer
+	storage        storage.Storage
 }
 
 func main() {
@@ -44,10 +65,58 @@ func main() {
 
 	models := database.NewModels(db)
 
+	mailSender, err := newMailer()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	keys, err := newKeySet()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	passwordPolicy, err := newPasswordPolicy()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	baseURL := env.GetEnvString("APP_BASE_URL", "http://localhost:8080")
+
+	webAuthn, err := newWebAuthn(baseURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	oidcProviders, err := newOIDCProviders(baseURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fileStorage, err := newStorage(baseURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	app := &application{
-		port:      env.GetEnvInt("PORT", 8080),
-		jwtSecret: env.GetEnvString("JWT_SECRET", "mysecret"),
-		models:    models,
+		port:           env.GetEnvInt("PORT", 8080),
+		keys:           keys,
+		baseURL:        baseURL,
+		mfaIssuer:      env.GetEnvString("MFA_ISSUER", "Event API"),
+		deletionGrace:  time.Duration(env.GetEnvInt("ACCOUNT_DELETION_GRACE_DAYS", 14)) * 24 * time.Hour,
+		passwords:      newPasswordHasher(),
+		passwordPolicy: passwordPolicy,
+		models:         models,
+		policy:         policy.New(&models.Roles, time.Minute),
+		webauthn:       webAuthn,
+		scimTokenHash:  newSCIMTokenHash(env.GetEnvString("SCIM_TOKEN", "")),
+		oidcProviders:  oidcProviders,
+		mailer:         mailSender,
+		storage:        fileStorage,
+	}
+
+	app.authenticators, err = newAuthenticators(app)
+	if err != nil {
+		log.Fatal(err)
 	}
 
 	if err := app.serve(); err != nil {
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"event-api-app/internal/database"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// calendarDefaultRange is how far ahead /me/calendar looks when no end is given.
+	calendarDefaultRange = 30 * 24 * time.Hour
+	// calendarMaxRange is the longest period /me/calendar returns at once.
+	calendarMaxRange = 366 * 24 * time.Hour
+)
+
+type meResponse struct {
+	User          *database.User           `json:"user"`
+	Profile       *database.Profile        `json:"profile"`
+	Organizations []*database.Organization `json:"organizations"`
+}
+
+type meEventsResponse struct {
+	Upcoming      []*database.UserEvent `json:"upcoming"`
+	Past          []*database.UserEvent `json:"past"`
+	UpcomingCount int                   `json:"upcoming_count"`
+	PastCount     int                   `json:"past_count"`
+}
+
+type meCalendarResponse struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	meEventsResponse
+}
+
+// splitByDate separates events that have not started yet from past ones.
+// Upcoming events are soonest first, past events most recent first.
+func splitByDate(events []*database.UserEvent, now time.Time) meEventsResponse {
+	response := meEventsResponse{Upcoming: []*database.UserEvent{}, Past: []*database.UserEvent{}}
+
+	for _, event := range events {
+		if event.Date.Before(now) {
+			response.Past = append(response.Past, event)
+		} else {
+			response.Upcoming = append(response.Upcoming, event)
+		}
+	}
+
+	slices.Reverse(response.Past)
+	response.UpcomingCount = len(response.Upcoming)
+	response.PastCount = len(response.Past)
+	return response
+}
+
+// parseCalendarTime accepts an RFC 3339 timestamp or a YYYY-MM-DD date (UTC midnight).
+func parseCalendarTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+// getMe returns the current user
+//
+// @Summary Get current user
+// @Description Get the authenticated user's account, public profile and organizations
+// @Tags me
+// @Produce json
+// @Success 200 {object} meResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /me [get]
+func (app *application) getMe(c *gin.Context) {
+	user := app.GetUserFromContext(c)
+
+	profile, err := app.models.Profiles.Get(user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve profile"})
+		return
+	}
+	if profile != nil {
+		app.withAvatarURLs(profile)
+	}
+
+	orgs, err := app.models.Organizations.GetForUser(user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organizations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, meResponse{User: user, Profile: profile, Organizations: orgs})
+}
+
+// getMyEvents lists the current user's events
+//
+// @Summary List my events
+// @Description List the events the authenticated user owns, attends or hosts, in every organization, split into upcoming and past with counts. Each event lists the user's roles on it.
+// @Tags me
+// @Produce json
+// @Param role query string false "owner, attendee or host (all if omitted)"
+// @Success 200 {object} meEventsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /me/events [get]
+func (app *application) getMyEvents(c *gin.Context) {
+	roles := []string{database.EventRoleOwner, database.EventRoleAttendee, database.EventRoleHost}
+
+	if role := c.Query("role"); role != "" {
+		if !slices.Contains(roles, role) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "role must be owner, attendee or host"})
+			return
+		}
+		roles = []string{role}
+	}
+
+	user := app.GetUserFromContext(c)
+
+	events, err := app.models.Events.GetForUser(user.Id, roles, nil, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, splitByDate(events, time.Now()))
+}
+
+// getMyCalendar lists the current user's events in a period
+//
+// @Summary My calendar
+// @Description List the events the authenticated user owns, attends or hosts between from (inclusive) and to (exclusive), split into upcoming and past with counts.
+// @Description Both accept RFC 3339 timestamps or YYYY-MM-DD dates. from defaults to now and to to 30 days after from; the period can be at most 366 days.
+// @Tags me
+// @Produce json
+// @Param from query string false "Start of the period"
+// @Param to query string false "End of the period"
+// @Success 200 {object} meCalendarResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /me/calendar [get]
+func (app *application) getMyCalendar(c *gin.Context) {
+	now := time.Now()
+	from, to := now, now.Add(calendarDefaultRange)
+	var err error
+
+	if value := c.Query("from"); value != "" {
+		if from, err = parseCalendarTime(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be an RFC 3339 timestamp or a YYYY-MM-DD date"})
+			return
+		}
+		to = from.Add(calendarDefaultRange)
+	}
+
+	if value := c.Query("to"); value != "" {
+		if to, err = parseCalendarTime(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be an RFC 3339 timestamp or a YYYY-MM-DD date"})
+			return
+		}
+	}
+
+	if !to.After(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must be after from"})
+		return
+	}
+
+	if to.Sub(from) > calendarMaxRange {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The period can be at most 366 days"})
+		return
+	}
+
+	user := app.GetUserFromContext(c)
+	roles := []string{database.EventRoleOwner, database.EventRoleAttendee, database.EventRoleHost}
+
+	events, err := app.models.Events.GetForUser(user.Id, roles, &from, &to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, meCalendarResponse{From: from, To: to, meEventsResponse: splitByDate(events, now)})
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"event-api-app/internal/totp"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	mfaChallengeTTL   = 5 * time.Minute
+	recoveryCodeCount = 10
+)
+
+type mfaChallengeResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+type totpEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type totpConfirmRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type mfaLoginRequest struct {
+	MFAToken     string `json:"mfa_token" binding:"required"`
+	Code         string `json:"code" binding:"required_without=RecoveryCode"`
+	RecoveryCode string `json:"recovery_code" binding:"required_without=Code"`
+}
+
+// generateRecoveryCodes returns n random one-time codes formatted as "xxxxx-xxxxx".
+func generateRecoveryCodes(n int) ([]string, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	codes := make([]string, n)
+
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+
+		code := strings.ToLower(encoding.EncodeToString(b))[:10]
+		codes[i] = code[:5] + "-" + code[5:]
+	}
+
+	return codes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
+
+// enrollTOTP starts TOTP enrollment for the current user
+//
+// @Summary Start TOTP enrollment
+// @Description Generate a new authenticator secret. MFA is only enforced after it is confirmed with a code.
+// @Tags mfa
+// @Produce json
+// @Success 200 {object} totpEnrollmentResponse
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/mfa/totp [post]
+func (app *application) enrollTOTP(c *gin.Context) {
+	user := app.GetUserFromContext(c)
+
+	enabled, err := app.models.MFA.IsEnabled(user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	if enabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "MFA is already enabled"})
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	if err := app.models.MFA.StartEnrollment(user.Id, secret); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start MFA enrollment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, totpEnrollmentResponse{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(app.mfaIssuer, user.Email, secret),
+	})
+}
+
+// getTOTPQRCode renders the pending enrollment as a QR code
+//
+// @Summary TOTP enrollment QR code
+// @Description PNG QR code of the otpauth URI for a pending (not yet confirmed) enrollment
+// @Tags mfa
+// @Produce png
+// @Success 200 {file} binary
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/mfa/totp/qr [get]
+func (app *application) getTOTPQRCode(c *gin.Context) {
+	user := app.GetUserFromContext(c)
+
+	enrollment, err := app.models.MFA.GetTOTP(user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	// 啟用後不再提供 QR code，避免 secret 外洩
+	if enrollment == nil || enrollment.Enabled {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No pending MFA enrollment"})
+		return
+	}
+
+	png, err := qrcode.Encode(totp.URI(app.mfaIssuer, user.Email, enrollment.Secret), qrcode.Medium, 256)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render QR code"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+// confirmTOTP enables MFA after the user proves their authenticator works
+//
+// @Summary Confirm TOTP enrollment
+// @Description Verify a code from the authenticator, enable MFA and return one-time recovery codes
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body totpConfirmRequest true "Current TOTP code"
+// @Success 200 {object} recoveryCodesResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/mfa/totp/confirm [post]
+func (app *application) confirmTOTP(c *gin.Context) {
+	var req totpConfirmRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := app.GetUserFromContext(c)
+
+	enrollment, err := app.models.MFA.GetTOTP(user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	if enrollment == nil || enrollment.Enabled {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No pending MFA enrollment"})
+		return
+	}
+
+	step, ok := totp.Validate(enrollment.Secret, req.Code, time.Now(), 1)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	codes, err := generateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	normalized := make([]string, len(codes))
+	for i, code := range codes {
+		normalized[i] = normalizeRecoveryCode(code)
+	}
+
+	if err := app.models.MFA.Enable(user.Id, step, normalized); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable MFA"})
+		return
+	}
+
+	c.JSON(http.StatusOK, recoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// loginMFA completes a login with a TOTP or recovery code
+//
+// @Summary Complete MFA login
+// @Description Exchange the mfa_token from /auth/login and a TOTP code (or recovery code) for a token pair
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body mfaLoginRequest true "MFA challenge response"
+// @Success 200 {object} loginResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/login/mfa [post]
+func (app *application) loginMFA(c *gin.Context) {
+	var req mfaLoginRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claims, err := app.parseToken(req.MFAToken, tokenUseMFA)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired MFA token"})
+		return
+	}
+
+	revoked, err := app.models.RevokedTokens.IsRevoked(claims.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	if revoked {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired MFA token"})
+		return
+	}
+
+	user, err := app.models.Users.Get(claims.UserId)
+	if err != nil || user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired MFA token"})
+		return
+	}
+
+	if rejectDisabled(c, user) {
+		return
+	}
+
+	// 錯誤的驗證碼與錯誤密碼共用同一組登入限制
+	ip := c.ClientIP()
+	blockedUntil, err := app.loginBlockedUntil(user.Email, ip)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	if !blockedUntil.IsZero() {
+		c.Header("Retry-After", strconv.Itoa(int(time.Until(blockedUntil).Seconds())+1))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed login attempts, please try again later"})
+		return
+	}
+
+	valid, err := app.checkSecondFactor(user.Id, req.Code, req.RecoveryCode)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	if !valid {
+		if err := app.recordLoginFailure(user.Email, ip, user); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+			return
+		}
+
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	// challenge token 僅能使用一次
+	if err := app.models.RevokedTokens.Add(claims.ID, claims.ExpiresAt.Time); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	if err := app.models.LoginAttempts.Reset(emailLoginKey(user.Email)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	app.issueLoginResponse(c, user)
+}
+
+// checkSecondFactor validates a TOTP code, or a recovery code when no TOTP code is given.
+func (app *application) checkSecondFactor(userId int, code, recoveryCode string) (bool, error) {
+	if code == "" {
+		return app.models.MFA.UseRecoveryCode(userId, normalizeRecoveryCode(recoveryCode))
+	}
+
+	enrollment, err := app.models.MFA.GetTOTP(userId)
+	if err != nil || enrollment == nil || !enrollment.Enabled {
+		return false, err
+	}
+
+	step, ok := totp.Validate(enrollment.Secret, code, time.Now(), 1)
+	if !ok {
+		return false, nil
+	}
+
+	return app.models.MFA.UseStep(userId, step)
+}
+
+// resetUserMFA removes a user's MFA enrollment
+//
+// @Summary Reset user MFA
+// @Description Remove a user's authenticator and recovery codes so they can log in with a password and enroll again (requires users.manage)
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /admin/users/{id}/mfa [delete]
+func (app *application) resetUserMFA(c *gin.Context) {
+	user := app.getTargetUser(c)
+	if user == nil {
+		return
+	}
+
+	if err := app.models.MFA.Delete(user.Id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset MFA"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "MFA has been reset"})
+}
@@ -4,13 +4,13 @@ import (
 	"event-api-app/internal/database"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
 // AuthMiddleware 的職責：
-// 1. 驗證 JWT token
+// 1. 驗證 JWT token（簽章、exp、iat）並確認所屬 session 未被撤銷，或驗證 API key
 // 2. 從資料庫載入用戶資料
 // 3. 將用戶資料傳遞給後續處理器
 func (app *application) AuthMiddleware() gin.HandlerFunc {
@@ -29,31 +29,75 @@ func (app *application) AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
+		var userId int
+
+		if strings.HasPrefix(tokenString, database.APIKeyPrefix) {
+			// API key：以雜湊查詢並記錄最後使用時間
+			key, err := app.models.APIKeys.GetByKey(tokenString)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+				c.Abort()
+				return
 			}
-			return []byte(app.jwtSecret), nil
-		})
 
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
-			return
+			if key == nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+				c.Abort()
+				return
+			}
+
+			if err := app.models.APIKeys.TouchLastUsed(key.Id); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+				c.Abort()
+				return
+			}
+
+			c.Set("api_key", key)
+			userId = key.UserId
+		} else {
+			claims, err := app.parseAccessToken(tokenString)
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+				c.Abort()
+				return
+			}
+
+			// 檢查 token 所屬的 session 是否仍有效（登出或被撤銷後即失效）
+			session, err := app.models.Sessions.Get(claims.SessionId)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+				c.Abort()
+				return
+			}
+
+			if session == nil || !session.IsActive() || session.UserId != claims.UserId {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+				c.Abort()
+				return
+			}
+
+			if time.Since(session.LastSeenAt) > sessionTouchInterval || session.IP != c.ClientIP() {
+				if err := app.models.Sessions.Touch(session.Id, c.ClientIP()); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+					c.Abort()
+					return
+				}
+			}
+
+			c.Set("claims", claims)
+			userId = claims.UserId
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		user, err := app.models.Users.Get(userId)
+		if err != nil || user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized access"})
 			c.Abort()
 			return
 		}
 
-		userId := claims["user_id"].(float64)
-
-		user, err := app.models.Users.Get(int(userId))
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized access"})
+		// 被停用的帳號即使持有有效 token 也一律拒絕
+		if user.IsDisabled() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account is disabled"})
 			c.Abort()
 			return
 		}
@@ -68,7 +112,7 @@ func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Organization")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
 
 		if c.Request.Method == "OPTIONS" {
@@ -104,3 +148,109 @@ func RequireVerifiedUser() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// 檢查用戶的角色是否具備指定權限
+func (app *application) RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, exists := c.Get("user")
+
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		u, ok := user.(*database.User)
+		if !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			c.Abort()
+			return
+		}
+
+		allowed, err := app.policy.Can(u.Role, permission)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+			c.Abort()
+			return
+		}
+
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// 檢查 API key 是否具備指定 scope；以 JWT 登入的用戶不受 scope 限制
+func (app *application) RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := app.GetAPIKeyFromContext(c)
+
+		if key != nil && !key.HasScope(scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API key is missing required scope: " + scope})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// 帳號管理類的路由僅允許以 JWT 登入的用戶，不接受 API key
+func (app *application) RequireUserSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if app.GetAPIKeyFromContext(c) != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This endpoint cannot be used with an API key"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// OptionalAuth 在有 Authorization 標頭時與 AuthMiddleware 相同，否則以匿名身分繼續
+func (app *application) OptionalAuth() gin.HandlerFunc {
+	auth := app.AuthMiddleware()
+
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+
+		auth(c)
+	}
+}
+
+// ResolveTenant 依 X-Organization 標頭（組織 slug）決定請求所屬的組織，未指定時使用預設組織
+func (app *application) ResolveTenant() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var org *database.Organization
+		var err error
+
+		if slug := c.GetHeader("X-Organization"); slug != "" {
+			org, err = app.models.Organizations.GetBySlug(slug)
+		} else {
+			org, err = app.models.Organizations.GetDefault()
+		}
+
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+			c.Abort()
+			return
+		}
+
+		if org == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+			c.Abort()
+			return
+		}
+
+		c.Set("organization", org)
+		c.Next()
+	}
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"event-api-app/internal/database"
+	"event-api-app/internal/env"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/oauth2"
+)
+
+const (
+	oidcLoginStateTTL  = 10 * time.Minute
+	oidcRequestTimeout = 10 * time.Second
+)
+
+// oidcProvider is an OpenID Connect identity provider. Its discovery document
+// is fetched on first use and cached, so the API can start while the
+// provider is unreachable.
+type oidcProvider struct {
+	name         string
+	issuer       string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	scopes       []string
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+// Errors returned by oidcProvider.exchange.
+var (
+	errOIDCExchange       = errors.New("failed to exchange authorization code")
+	errOIDCMissingIDToken = errors.New("identity provider did not return an ID token")
+	errOIDCInvalidIDToken = errors.New("invalid ID token")
+)
+
+type oidcClaims struct {
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+}
+
+// newOIDCProviders 讀取 OIDC_PROVIDERS（以逗號分隔的名稱），每個名稱 <NAME> 需設定
+// OIDC_<NAME>_ISSUER_URL、OIDC_<NAME>_CLIENT_ID、OIDC_<NAME>_CLIENT_SECRET，
+// 可選 OIDC_<NAME>_SCOPES 與 OIDC_<NAME>_REDIRECT_URL
+func newOIDCProviders(baseURL string) (map[string]*oidcProvider, error) {
+	providers := map[string]*oidcProvider{}
+
+	for _, name := range strings.Split(env.GetEnvString("OIDC_PROVIDERS", ""), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		p := &oidcProvider{
+			name:         name,
+			issuer:       env.GetEnvString(prefix+"ISSUER_URL", ""),
+			clientID:     env.GetEnvString(prefix+"CLIENT_ID", ""),
+			clientSecret: env.GetEnvString(prefix+"CLIENT_SECRET", ""),
+			redirectURL:  env.GetEnvString(prefix+"REDIRECT_URL", baseURL+"/api/v1/auth/oidc/"+name+"/callback"),
+			scopes:       strings.Fields(env.GetEnvString(prefix+"SCOPES", "openid email profile")),
+		}
+
+		if p.issuer == "" || p.clientID == "" {
+			return nil, fmt.Errorf("OIDC provider %q needs %sISSUER_URL and %sCLIENT_ID", name, prefix, prefix)
+		}
+
+		providers[name] = p
+	}
+
+	return providers, nil
+}
+
+// discover returns the provider's metadata, fetching it if needed.
+func (p *oidcProvider) discover(ctx context.Context) (*oidc.Provider, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.provider == nil {
+		provider, err := oidc.NewProvider(ctx, p.issuer)
+		if err != nil {
+			return nil, err
+		}
+		p.provider = provider
+	}
+
+	return p.provider, nil
+}
+
+func (p *oidcProvider) oauth2Config(provider *oidc.Provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     p.clientID,
+		ClientSecret: p.clientSecret,
+		RedirectURL:  p.redirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       p.scopes,
+	}
+}
+
+// authCodeURL returns the authorization URL for the login state, carrying its
+// nonce and the PKCE challenge of its code verifier.
+func (p *oidcProvider) authCodeURL(provider *oidc.Provider, state string, loginState *database.OIDCLoginState) string {
+	return p.oauth2Config(provider).AuthCodeURL(state,
+		oidc.Nonce(loginState.Nonce),
+		oauth2.S256ChallengeOption(loginState.CodeVerifier),
+	)
+}
+
+// exchange trades the authorization code for tokens and validates the ID
+// token's signature, issuer, audience, expiry and nonce.
+func (p *oidcProvider) exchange(ctx context.Context, provider *oidc.Provider, code string, loginState *database.OIDCLoginState) (*oidc.IDToken, oidcClaims, error) {
+	var claims oidcClaims
+
+	token, err := p.oauth2Config(provider).Exchange(ctx, code, oauth2.VerifierOption(loginState.CodeVerifier))
+	if err != nil {
+		return nil, claims, fmt.Errorf("%w: %v", errOIDCExchange, err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, claims, errOIDCMissingIDToken
+	}
+
+	// 驗證簽章、issuer、audience 與有效期限，並確認 nonce 與登入時產生的相同
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: p.clientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, claims, fmt.Errorf("%w: %v", errOIDCInvalidIDToken, err)
+	}
+
+	if idToken.Nonce != loginState.Nonce {
+		return nil, claims, fmt.Errorf("%w: nonce mismatch", errOIDCInvalidIDToken)
+	}
+
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, claims, fmt.Errorf("%w: %v", errOIDCInvalidIDToken, err)
+	}
+
+	return idToken, claims, nil
+}
+
+// getOIDCProvider finds the provider named by the :provider path parameter
+// and loads its metadata, writing an error response and returning nil when it cannot.
+func (app *application) getOIDCProvider(ctx context.Context, c *gin.Context) (*oidcProvider, *oidc.Provider) {
+	p, ok := app.oidcProviders[c.Param("provider")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown identity provider"})
+		return nil, nil
+	}
+
+	provider, err := p.discover(ctx)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Identity provider is unavailable"})
+		return nil, nil
+	}
+
+	return p, provider
+}
+
+// oidcLogin redirects to an external identity provider
+//
+// @Summary Sign in with an identity provider
+// @Description Redirect to the provider's authorization endpoint using the authorization code flow with PKCE
+// @Tags authentication
+// @Param provider path string true "Provider name"
+// @Success 302 "Redirect to the identity provider"
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Failure 502 {object} map[string]string
+// @Router /auth/oidc/{provider}/login [get]
+func (app *application) oidcLogin(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), oidcRequestTimeout)
+	defer cancel()
+
+	p, provider := app.getOIDCProvider(ctx, c)
+	if p == nil {
+		return
+	}
+
+	nonce, err := newTokenId()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	loginState := &database.OIDCLoginState{
+		Provider:     p.name,
+		Nonce:        nonce,
+		CodeVerifier: oauth2.GenerateVerifier(),
+	}
+
+	state, err := app.models.Identities.CreateLoginState(loginState, oidcLoginStateTTL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	c.Redirect(http.StatusFound, p.authCodeURL(provider, state, loginState))
+}
+
+// oidcCallback completes a sign-in with an external identity provider
+//
+// @Summary Identity provider callback
+// @Description Exchange the authorization code, validate the ID token and sign in. The first login links the verified account with the same email, or creates one.
+// @Description An existing unverified account with that email is not linked (409).
+// @Tags authentication
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param code query string true "Authorization code"
+// @Param state query string true "State returned by the provider"
+// @Success 200 {object} loginResponse
+// @Success 202 {object} mfaChallengeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Failure 502 {object} map[string]string
+// @Router /auth/oidc/{provider}/callback [get]
+func (app *application) oidcCallback(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), oidcRequestTimeout)
+	defer cancel()
+
+	if errCode := c.Query("error"); errCode != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Identity provider returned an error: " + errCode, "description": c.Query("error_description")})
+		return
+	}
+
+	p, provider := app.getOIDCProvider(ctx, c)
+	if p == nil {
+		return
+	}
+
+	loginState, err := app.models.Identities.ConsumeLoginState(c.Query("state"))
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired login state"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	if loginState.Provider != p.name || c.Query("code") == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired login state"})
+		return
+	}
+
+	idToken, claims, err := p.exchange(ctx, provider, c.Query("code"), loginState)
+	if err != nil {
+		switch {
+		case errors.Is(err, errOIDCExchange):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to exchange authorization code"})
+		case errors.Is(err, errOIDCMissingIDToken):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Identity provider did not return an ID token"})
+		default:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid ID token"})
+		}
+		return
+	}
+
+	user := app.resolveOIDCUser(c, p.name, idToken.Subject, claims)
+	if user == nil {
+		return
+	}
+
+	app.completeLogin(c, user)
+}
+
+// resolveOIDCUser returns the user linked to the provider's subject. On the
+// first login it links the verified account with the same verified email, or
+// creates one. An unverified account with that email is never linked. It
+// writes an error response and returns nil when it cannot.
+func (app *application) resolveOIDCUser(c *gin.Context, provider, subject string, claims oidcClaims) *database.User {
+	userId, err := app.models.Identities.GetUserId(provider, subject)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return nil
+	}
+
+	if userId != 0 {
+		user, err := app.models.Users.Get(userId)
+		if err != nil || user == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+			return nil
+		}
+		return user
+	}
+
+	// 只有經 IdP 驗證過的 email 才能用來連結或建立帳號
+	if claims.Email == "" || !claims.EmailVerified {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Identity provider did not return a verified email"})
+		return nil
+	}
+
+	user, err := app.models.Users.GetByEmail(claims.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return nil
+	}
+
+	// 未驗證的帳號可能是他人搶先以此 email 註冊的；連結後對方仍保有密碼、session 與 API key
+	if user != nil && !user.Verified {
+		c.JSON(http.StatusConflict, gin.H{"error": "An unverified account already uses this email. Verify it or sign in with its password first."})
+		return nil
+	}
+
+	if user == nil {
+		user, err = app.provisionUser(claims.Email, claims.Name)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create account"})
+			return nil
+		}
+	}
+
+	if err := app.models.Identities.Link(user.Id, provider, subject, claims.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return nil
+	}
+
+	return user
+}
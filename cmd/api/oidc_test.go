@@ -0,0 +1,347 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"event-api-app/internal/database"
+	"event-api-app/internal/signing"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+// mockOIDCProvider is a minimal OpenID Connect provider: discovery, an
+// authorization endpoint that approves every request, a token endpoint that
+// checks PKCE, and a JWKS endpoint.
+type mockOIDCProvider struct {
+	server   *httptest.Server
+	keys     *signing.KeySet
+	clientID string
+
+	// signer signs ID tokens instead of keys when set.
+	signer *signing.KeySet
+
+	// claims may change the ID token claims before they are signed.
+	claims func(jwt.MapClaims)
+
+	mu    sync.Mutex
+	codes map[string]mockAuthorization
+}
+
+type mockAuthorization struct {
+	challenge   string
+	nonce       string
+	redirectURI string
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	t.Helper()
+
+	m := &mockOIDCProvider{
+		keys:     newTestRSAKeySet(t),
+		clientID: "event-api",
+		codes:    map[string]mockAuthorization{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", m.discovery)
+	mux.HandleFunc("/authorize", m.authorize)
+	mux.HandleFunc("/token", m.token)
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeTestJSON(w, http.StatusOK, m.keys.JWKS())
+	})
+
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+
+	return m
+}
+
+// newTestRSAKeySet returns a KeySet holding a fresh RSA key with kid "test".
+func newTestRSAKeySet(t *testing.T) *signing.KeySet {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	if err := os.WriteFile(filepath.Join(dir, "test.pem"), pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := signing.LoadDir("RS256", dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return keys
+}
+
+func writeTestJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func (m *mockOIDCProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeTestJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                m.server.URL,
+		"authorization_endpoint":                m.server.URL + "/authorize",
+		"token_endpoint":                        m.server.URL + "/token",
+		"jwks_uri":                              m.server.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (m *mockOIDCProvider) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	if q.Get("response_type") != "code" || q.Get("client_id") != m.clientID || q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+
+	code, err := newTokenId()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	m.mu.Lock()
+	m.codes[code] = mockAuthorization{challenge: q.Get("code_challenge"), nonce: q.Get("nonce"), redirectURI: q.Get("redirect_uri")}
+	m.mu.Unlock()
+
+	redirect, _ := url.Parse(q.Get("redirect_uri"))
+	redirect.RawQuery = url.Values{"code": {code}, "state": {q.Get("state")}}.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (m *mockOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeTestJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	m.mu.Lock()
+	auth, ok := m.codes[r.PostForm.Get("code")]
+	delete(m.codes, r.PostForm.Get("code"))
+	m.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	if !ok || r.PostForm.Get("grant_type") != "authorization_code" || challenge != auth.challenge || r.PostForm.Get("redirect_uri") != auth.redirectURI {
+		writeTestJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            m.server.URL,
+		"sub":            "user-1",
+		"aud":            m.clientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          auth.nonce,
+		"email":          "staff@example.com",
+		"email_verified": true,
+		"name":           "Staff Member",
+	}
+	if m.claims != nil {
+		m.claims(claims)
+	}
+
+	signer := m.keys
+	if m.signer != nil {
+		signer = m.signer
+	}
+
+	idToken, err := signer.Sign(claims)
+	if err != nil {
+		writeTestJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeTestJSON(w, http.StatusOK, map[string]any{
+		"access_token": "access",
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+// login runs the authorization request against the mock provider the way a
+// browser would and returns the code and state sent to the redirect URL.
+func (m *mockOIDCProvider) login(t *testing.T, authURL string) (string, string) {
+	t.Helper()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	res, err := client.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusFound {
+		t.Fatalf("authorization request: got status %d", res.StatusCode)
+	}
+
+	location, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func (m *mockOIDCProvider) relyingParty() *oidcProvider {
+	return &oidcProvider{
+		name:         "mock",
+		issuer:       m.server.URL,
+		clientID:     m.clientID,
+		clientSecret: "secret",
+		redirectURL:  "http://localhost:8080/api/v1/auth/oidc/mock/callback",
+		scopes:       []string{"openid", "email", "profile"},
+	}
+}
+
+func newTestLoginState() *database.OIDCLoginState {
+	return &database.OIDCLoginState{Provider: "mock", Nonce: "nonce-1", CodeVerifier: oauth2.GenerateVerifier()}
+}
+
+func TestOIDCLogin(t *testing.T) {
+	m := newMockOIDCProvider(t)
+	p := m.relyingParty()
+	ctx := context.Background()
+
+	provider, err := p.discover(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loginState := newTestLoginState()
+	code, state := m.login(t, p.authCodeURL(provider, "state-1", loginState))
+
+	if state != "state-1" {
+		t.Fatalf("got state %q, want %q", state, "state-1")
+	}
+
+	idToken, claims, err := p.exchange(ctx, provider, code, loginState)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if idToken.Subject != "user-1" {
+		t.Errorf("got subject %q, want %q", idToken.Subject, "user-1")
+	}
+
+	want := oidcClaims{Email: "staff@example.com", EmailVerified: true, Name: "Staff Member"}
+	if claims != want {
+		t.Errorf("got claims %+v, want %+v", claims, want)
+	}
+}
+
+func TestOIDCExchangeRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		claims  func(jwt.MapClaims)
+		modify  func(*database.OIDCLoginState)
+		wantErr error
+	}{
+		{
+			name:    "wrong PKCE verifier",
+			modify:  func(s *database.OIDCLoginState) { s.CodeVerifier = oauth2.GenerateVerifier() },
+			wantErr: errOIDCExchange,
+		},
+		{
+			name:    "nonce mismatch",
+			modify:  func(s *database.OIDCLoginState) { s.Nonce = "another-nonce" },
+			wantErr: errOIDCInvalidIDToken,
+		},
+		{
+			name:    "wrong audience",
+			claims:  func(c jwt.MapClaims) { c["aud"] = "another-client" },
+			wantErr: errOIDCInvalidIDToken,
+		},
+		{
+			name:    "wrong issuer",
+			claims:  func(c jwt.MapClaims) { c["iss"] = "https://attacker.example.com" },
+			wantErr: errOIDCInvalidIDToken,
+		},
+		{
+			name:    "expired",
+			claims:  func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+			wantErr: errOIDCInvalidIDToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMockOIDCProvider(t)
+			m.claims = tt.claims
+			p := m.relyingParty()
+			ctx := context.Background()
+
+			provider, err := p.discover(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			loginState := newTestLoginState()
+			code, _ := m.login(t, p.authCodeURL(provider, "state-1", loginState))
+
+			if tt.modify != nil {
+				tt.modify(loginState)
+			}
+
+			if _, _, err := p.exchange(ctx, provider, code, loginState); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOIDCExchangeRejectsForeignSignature(t *testing.T) {
+	m := newMockOIDCProvider(t)
+	p := m.relyingParty()
+	ctx := context.Background()
+
+	provider, err := p.discover(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 以不在 JWKS 中的金鑰簽發 ID token（kid 相同）
+	m.signer = newTestRSAKeySet(t)
+
+	loginState := newTestLoginState()
+	code, _ := m.login(t, p.authCodeURL(provider, "state-1", loginState))
+
+	if _, _, err := p.exchange(ctx, provider, code, loginState); !errors.Is(err, errOIDCInvalidIDToken) {
+		t.Fatalf("got error %v, want %v", err, errOIDCInvalidIDToken)
+	}
+}
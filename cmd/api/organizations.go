@@ -0,0 +1,502 @@
+package main
+
+import (
+	"errors"
+	"event-api-app/internal/database"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// orgSlugPattern allows lower-case words joined by single hyphens.
+var orgSlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// orgRoleRank orders organization roles by privilege.
+var orgRoleRank = map[string]int{
+	database.OrgRoleMember: 1,
+	database.OrgRoleAdmin:  2,
+	database.OrgRoleOwner:  3,
+}
+
+type createOrganizationRequest struct {
+	Slug string `json:"slug" binding:"required,min=2,max=50"`
+	Name string `json:"name" binding:"required,min=2,max=100"`
+}
+
+type updateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,min=2,max=100"`
+}
+
+type addOrganizationMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"omitempty,oneof=owner admin member"`
+}
+
+type setOrganizationMemberRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=owner admin member"`
+}
+
+// isOrganizationAdmin reports whether the user is an owner or admin of the organization.
+func (app *application) isOrganizationAdmin(orgId, userId int) (bool, error) {
+	role, err := app.models.Organizations.GetMemberRole(orgId, userId)
+	if err != nil {
+		return false, err
+	}
+	return orgRoleRank[role] >= orgRoleRank[database.OrgRoleAdmin], nil
+}
+
+// getMemberOrganization loads the organization named by the :org path
+// parameter with the current user's role in it. Organizations the user does
+// not belong to are reported as not found. It writes an error response and
+// returns nil when the user's role is below minRole.
+func (app *application) getMemberOrganization(c *gin.Context, minRole string) *database.Organization {
+	org, err := app.models.Organizations.GetBySlug(c.Param("org"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organization"})
+		return nil
+	}
+
+	user := app.GetUserFromContext(c)
+
+	if org != nil {
+		org.Role, err = app.models.Organizations.GetMemberRole(org.Id, user.Id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organization"})
+			return nil
+		}
+	}
+
+	if org == nil || org.Role == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+		return nil
+	}
+
+	if orgRoleRank[org.Role] < orgRoleRank[minRole] {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Your role in this organization does not allow this"})
+		return nil
+	}
+
+	return org
+}
+
+// createOrganization creates an organization
+//
+// @Summary Create organization
+// @Description Create an organization. The caller becomes its owner.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param request body createOrganizationRequest true "Slug (lower-case letters, digits and hyphens) and name"
+// @Success 201 {object} database.Organization
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /orgs [post]
+func (app *application) createOrganization(c *gin.Context) {
+	var req createOrganizationRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !orgSlugPattern.MatchString(req.Slug) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Slug may only contain lower-case letters, digits and single hyphens"})
+		return
+	}
+
+	user := app.GetUserFromContext(c)
+	org := &database.Organization{Slug: req.Slug, Name: req.Name}
+
+	if err := app.models.Organizations.Insert(org, user.Id); err != nil {
+		if errors.Is(err, database.ErrDuplicateSlug) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Slug is already in use"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create organization"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, org)
+}
+
+// getOrganizations lists the caller's organizations
+//
+// @Summary List my organizations
+// @Description List the organizations the current user belongs to, with the user's role in each
+// @Tags organizations
+// @Produce json
+// @Success 200 {array} database.Organization
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /orgs [get]
+func (app *application) getOrganizations(c *gin.Context) {
+	user := app.GetUserFromContext(c)
+
+	orgs, err := app.models.Organizations.GetForUser(user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organizations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, orgs)
+}
+
+// getOrganization returns one of the caller's organizations
+//
+// @Summary Get organization
+// @Tags organizations
+// @Produce json
+// @Param org path string true "Organization slug"
+// @Success 200 {object} database.Organization
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /orgs/{org} [get]
+func (app *application) getOrganization(c *gin.Context) {
+	org := app.getMemberOrganization(c, database.OrgRoleMember)
+	if org == nil {
+		return
+	}
+
+	c.JSON(http.StatusOK, org)
+}
+
+// updateOrganization renames an organization
+//
+// @Summary Rename organization
+// @Description Change the organization's name (owners and admins). The slug cannot be changed.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param org path string true "Organization slug"
+// @Param request body updateOrganizationRequest true "New name"
+// @Success 200 {object} database.Organization
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /orgs/{org} [put]
+func (app *application) updateOrganization(c *gin.Context) {
+	org := app.getMemberOrganization(c, database.OrgRoleAdmin)
+	if org == nil {
+		return
+	}
+
+	var req updateOrganizationRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := app.models.Organizations.Rename(org.Id, req.Name); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update organization"})
+		return
+	}
+
+	org.Name = req.Name
+	c.JSON(http.StatusOK, org)
+}
+
+// deleteOrganization deletes an organization
+//
+// @Summary Delete organization
+// @Description Delete the organization with all of its events (owners only). The default organization cannot be deleted.
+// @Tags organizations
+// @Param org path string true "Organization slug"
+// @Success 204 "Organization deleted"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /orgs/{org} [delete]
+func (app *application) deleteOrganization(c *gin.Context) {
+	org := app.getMemberOrganization(c, database.OrgRoleOwner)
+	if org == nil {
+		return
+	}
+
+	if org.IsDefault {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The default organization cannot be deleted"})
+		return
+	}
+
+	if err := app.models.Organizations.Delete(org.Id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete organization"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// getOrganizationMembers lists an organization's members
+//
+// @Summary List organization members
+// @Description List the members and their email addresses (owners and admins)
+// @Tags organizations
+// @Produce json
+// @Param org path string true "Organization slug"
+// @Success 200 {array} database.OrganizationMember
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /orgs/{org}/members [get]
+func (app *application) getOrganizationMembers(c *gin.Context) {
+	// 成員清單包含 email，僅限管理者查看
+	org := app.getMemberOrganization(c, database.OrgRoleAdmin)
+	if org == nil {
+		return
+	}
+
+	members, err := app.models.Organizations.GetMembers(org.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve members"})
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// addOrganizationMember adds a user to an organization
+//
+// @Summary Add organization member
+// @Description Add an existing user by email (owners and admins). Only owners can add owners. Role defaults to member.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param org path string true "Organization slug"
+// @Param request body addOrganizationMemberRequest true "User email and role"
+// @Success 201 {array} database.OrganizationMember
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /orgs/{org}/members [post]
+func (app *application) addOrganizationMember(c *gin.Context) {
+	org := app.getMemberOrganization(c, database.OrgRoleAdmin)
+	if org == nil {
+		return
+	}
+
+	var req addOrganizationMemberRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Role == "" {
+		req.Role = database.OrgRoleMember
+	}
+
+	if orgRoleRank[req.Role] > orgRoleRank[org.Role] {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot grant a role above your own"})
+		return
+	}
+
+	member, err := app.models.Users.GetByEmail(req.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
+		return
+	}
+
+	if member == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	existingRole, err := app.models.Organizations.GetMemberRole(org.Id, member.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add member"})
+		return
+	}
+
+	if existingRole != "" {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already a member"})
+		return
+	}
+
+	if err := app.models.Organizations.SetMember(org.Id, member.Id, req.Role); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add member"})
+		return
+	}
+
+	app.respondOrganizationMembers(c, http.StatusCreated, org.Id)
+}
+
+// setOrganizationMemberRole changes a member's role
+//
+// @Summary Change organization member role
+// @Description Change a member's role (owners and admins). Only owners can grant or take away the owner role, and the last owner cannot be demoted.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param org path string true "Organization slug"
+// @Param userId path int true "User ID"
+// @Param request body setOrganizationMemberRoleRequest true "New role"
+// @Success 200 {array} database.OrganizationMember
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /orgs/{org}/members/{userId} [put]
+func (app *application) setOrganizationMemberRole(c *gin.Context) {
+	org := app.getMemberOrganization(c, database.OrgRoleAdmin)
+	if org == nil {
+		return
+	}
+
+	userId, currentRole := app.getOrganizationMemberParam(c, org)
+	if currentRole == "" {
+		return
+	}
+
+	var req setOrganizationMemberRoleRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 只有 owner 能授予或移除 owner 角色
+	if (req.Role == database.OrgRoleOwner || currentRole == database.OrgRoleOwner) && org.Role != database.OrgRoleOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only owners can change the owner role"})
+		return
+	}
+
+	if currentRole == database.OrgRoleOwner && req.Role != database.OrgRoleOwner && !app.hasAnotherOwner(c, org.Id) {
+		return
+	}
+
+	if err := app.models.Organizations.SetMember(org.Id, userId, req.Role); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update member"})
+		return
+	}
+
+	app.respondOrganizationMembers(c, http.StatusOK, org.Id)
+}
+
+// removeOrganizationMember removes a member from an organization
+//
+// @Summary Remove organization member
+// @Description Remove a member (owners and admins), or leave the organization by removing yourself. The last owner cannot leave, and nobody can leave the default organization.
+// @Tags organizations
+// @Param org path string true "Organization slug"
+// @Param userId path int true "User ID"
+// @Success 204 "Member removed"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /orgs/{org}/members/{userId} [delete]
+func (app *application) removeOrganizationMember(c *gin.Context) {
+	org := app.getMemberOrganization(c, database.OrgRoleMember)
+	if org == nil {
+		return
+	}
+
+	userId, currentRole := app.getOrganizationMemberParam(c, org)
+	if currentRole == "" {
+		return
+	}
+
+	user := app.GetUserFromContext(c)
+
+	// 一般成員只能自行退出；移除 owner 需要 owner 權限
+	if userId != user.Id && orgRoleRank[org.Role] < orgRoleRank[database.OrgRoleAdmin] {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Your role in this organization does not allow this"})
+		return
+	}
+
+	if userId != user.Id && currentRole == database.OrgRoleOwner && org.Role != database.OrgRoleOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only owners can remove an owner"})
+		return
+	}
+
+	if org.IsDefault {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Members cannot be removed from the default organization"})
+		return
+	}
+
+	if currentRole == database.OrgRoleOwner && !app.hasAnotherOwner(c, org.Id) {
+		return
+	}
+
+	if _, err := app.models.Organizations.RemoveMember(org.Id, userId); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove member"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// getOrganizationMemberParam returns the user named by the :userId path
+// parameter and their role. It writes an error response and returns an
+// empty role if the user is not a member.
+func (app *application) getOrganizationMemberParam(c *gin.Context, org *database.Organization) (int, string) {
+	userId, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return 0, ""
+	}
+
+	role, err := app.models.Organizations.GetMemberRole(org.Id, userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve member"})
+		return 0, ""
+	}
+
+	if role == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return 0, ""
+	}
+
+	return userId, role
+}
+
+// hasAnotherOwner writes a 400 response and returns false if the organization
+// has a single owner, who therefore cannot be demoted or removed.
+func (app *application) hasAnotherOwner(c *gin.Context, orgId int) bool {
+	owners, err := app.models.Organizations.CountOwners(orgId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return false
+	}
+
+	if owners <= 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "An organization needs at least one owner"})
+		return false
+	}
+
+	return true
+}
+
+func (app *application) respondOrganizationMembers(c *gin.Context, status, orgId int) {
+	members, err := app.models.Organizations.GetMembers(orgId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve members"})
+		return
+	}
+
+	c.JSON(status, members)
+}
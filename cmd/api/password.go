@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"event-api-app/internal/database"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const passwordResetTTL = time.Hour
+
+type forgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// forgotPassword emails a password reset token to the account owner
+//
+// @Summary Request a password reset
+// @Description Send a single-use password reset token to the given email. The response does not reveal whether the email exists.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body forgotPasswordRequest true "Account email"
+// @Success 202 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/password/forgot [post]
+func (app *application) forgotPassword(c *gin.Context) {
+	var req forgotPasswordRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := app.models.Users.GetByEmail(req.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	if user != nil {
+		token, err := app.models.PasswordResets.Create(user.Id, passwordResetTTL)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+			return
+		}
+
+		app.sendEmail(user.Email, "password_reset.tmpl", map[string]any{
+			"Name":  user.Name,
+			"Token": token,
+		})
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If an account with that email exists, a password reset email has been sent"})
+}
+
+// resetPassword sets a new password using a reset token
+//
+// @Summary Reset password
+// @Description Consume a password reset token and set a new password that satisfies the password policy. Every session and API key of the account is revoked.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body resetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/password/reset [post]
+func (app *application) resetPassword(c *gin.Context) {
+	var req resetPasswordRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 先確認 token 有效並取得帳號資料，才能檢查新密碼是否與 email 或姓名相似
+	userId, err := app.models.PasswordResets.GetUserId(req.Token)
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	user, err := app.models.Users.Get(userId)
+	if err != nil || user == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	if !app.checkPasswordPolicy(c, req.Password, user.Email, user.Name) {
+		return
+	}
+
+	hashedPassword, err := app.passwords.Hash(req.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	userId, err = app.models.PasswordResets.Reset(req.Token, hashedPassword)
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	// 重設密碼後，所有裝置一律登出，API key 也一併撤銷
+	if err := app.models.Sessions.RevokeAllForUser(userId, 0); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	if err := app.models.APIKeys.DeleteAllForUser(userId); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset"})
+}
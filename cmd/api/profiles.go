@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"event-api-app/internal/database"
+	"event-api-app/internal/thumbnail"
+	"image/png"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// avatarMaxBytes limits the size of uploaded avatar files.
+	avatarMaxBytes = 5 << 20
+	// avatarMaxPixels limits the dimensions of uploaded avatars, so that small
+	// files cannot expand into huge images when decoded.
+	avatarMaxPixels = 4096 * 4096
+
+	avatarSize          = 256
+	avatarThumbnailSize = 64
+)
+
+// localePattern accepts BCP 47 style language tags such as "en", "zh-TW" or "sr-Latn-RS".
+var localePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// Fields that are left out keep their current value.
+type updateProfileRequest struct {
+	Bio          *string                 `json:"bio" binding:"omitempty,max=1000"`
+	Timezone     *string                 `json:"timezone" binding:"omitempty,max=64"`
+	Locale       *string                 `json:"locale" binding:"omitempty,max=35"`
+	Organization *string                 `json:"organization" binding:"omitempty,max=100"`
+	Links        *[]database.ProfileLink `json:"links" binding:"omitempty,max=5,dive"`
+}
+
+// avatarKeys returns the storage keys of the full-size avatar and its thumbnail.
+func avatarKeys(key string) (string, string) {
+	return key + "-" + strconv.Itoa(avatarSize) + ".png", key + "-" + strconv.Itoa(avatarThumbnailSize) + ".png"
+}
+
+// withAvatarURLs fills in the avatar URLs of the profile.
+func (app *application) withAvatarURLs(profile *database.Profile) *database.Profile {
+	if profile.AvatarKey != "" {
+		full, thumb := avatarKeys(profile.AvatarKey)
+		profile.AvatarURL = app.storage.URL(full)
+		profile.AvatarThumbnailURL = app.storage.URL(thumb)
+	}
+	return profile
+}
+
+// deleteAvatarFiles removes an avatar's files from storage. Failures are only
+// logged: the avatar is no longer referenced either way.
+func (app *application) deleteAvatarFiles(key string) {
+	if key == "" {
+		return
+	}
+
+	full, thumb := avatarKeys(key)
+	for _, file := range []string{full, thumb} {
+		if err := app.storage.Delete(file); err != nil {
+			log.Printf("delete avatar %s: %v", file, err)
+		}
+	}
+}
+
+// deleteUserAccount deletes the user immediately, together with the avatar
+// files that the database cascade does not reach.
+func (app *application) deleteUserAccount(id int) error {
+	key, err := app.models.Profiles.GetAvatarKey(id)
+	if err != nil {
+		return err
+	}
+
+	if err := app.models.Users.Delete(id); err != nil {
+		return err
+	}
+
+	app.deleteAvatarFiles(key)
+	return nil
+}
+
+// getUserProfile returns a user's public profile
+//
+// @Summary Get public profile
+// @Description Get a user's public profile. The email address is never included. Disabled accounts and accounts pending deletion are not found.
+// @Tags user
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} database.Profile
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id} [get]
+func (app *application) getUserProfile(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	profile, err := app.models.Profiles.Get(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve profile"})
+		return
+	}
+
+	if profile == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, app.withAvatarURLs(profile))
+}
+
+// getMyProfile returns the current user's profile
+//
+// @Summary Get my profile
+// @Description Get the current user's public profile as others see it
+// @Tags user
+// @Produce json
+// @Success 200 {object} database.Profile
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/profile [get]
+func (app *application) getMyProfile(c *gin.Context) {
+	profile := app.getCurrentProfile(c)
+	if profile == nil {
+		return
+	}
+
+	c.JSON(http.StatusOK, app.withAvatarURLs(profile))
+}
+
+// updateProfile changes the current user's profile details
+//
+// @Summary Update my profile
+// @Description Change the bio, timezone (IANA name such as "Asia/Taipei"), locale (such as "zh-TW"), organization and up to 5 links. Fields that are left out are not changed.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param request body updateProfileRequest true "Profile fields"
+// @Success 200 {object} database.Profile
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/profile [put]
+func (app *application) updateProfile(c *gin.Context) {
+	var req updateProfileRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Timezone != nil && *req.Timezone != "" {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "Local" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown timezone"})
+			return
+		}
+	}
+
+	if req.Locale != nil && *req.Locale != "" && !localePattern.MatchString(*req.Locale) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid locale"})
+		return
+	}
+
+	if req.Links != nil {
+		for _, link := range *req.Links {
+			// 只接受 http(s) 連結，避免 javascript: 等 scheme 出現在個人頁面
+			u, err := url.Parse(link.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Links must be http or https URLs"})
+				return
+			}
+		}
+	}
+
+	profile := app.getCurrentProfile(c)
+	if profile == nil {
+		return
+	}
+
+	if req.Bio != nil {
+		profile.Bio = *req.Bio
+	}
+	if req.Timezone != nil {
+		profile.Timezone = *req.Timezone
+	}
+	if req.Locale != nil {
+		profile.Locale = *req.Locale
+	}
+	if req.Organization != nil {
+		profile.Organization = *req.Organization
+	}
+	if req.Links != nil {
+		profile.Links = *req.Links
+	}
+
+	if err := app.models.Profiles.Update(profile); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
+		return
+	}
+
+	c.JSON(http.StatusOK, app.withAvatarURLs(profile))
+}
+
+// uploadAvatar sets the current user's avatar
+//
+// @Summary Upload avatar
+// @Description Upload a JPEG, PNG or GIF image (at most 5 MB and 4096x4096 pixels). It is cropped to a square and stored as a 256px avatar and a 64px thumbnail.
+// @Tags user
+// @Accept multipart/form-data
+// @Produce json
+// @Param avatar formData file true "Image file"
+// @Success 200 {object} database.Profile
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/profile/avatar [put]
+func (app *application) uploadAvatar(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, avatarMaxBytes+1<<20)
+
+	file, err := c.FormFile("avatar")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Avatar must be at most 5 MB"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing avatar file"})
+		return
+	}
+
+	if file.Size > avatarMaxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Avatar must be at most 5 MB"})
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unable to read avatar"})
+		return
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(io.LimitReader(src, avatarMaxBytes))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unable to read avatar"})
+		return
+	}
+
+	img, err := thumbnail.Decode(data, avatarMaxPixels)
+	if err != nil {
+		switch {
+		case errors.Is(err, thumbnail.ErrTooLarge):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Avatar must be at most 4096x4096 pixels"})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Avatar must be a JPEG, PNG or GIF image"})
+		}
+		return
+	}
+
+	user := app.GetUserFromContext(c)
+
+	// 每次上傳使用新的 key，讓舊網址的快取不會顯示新頭像
+	id, err := newTokenId()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save avatar"})
+		return
+	}
+	key := "avatars/" + strconv.Itoa(user.Id) + "/" + id[:16]
+
+	full, thumb := avatarKeys(key)
+	for _, size := range []struct {
+		key  string
+		size int
+	}{{full, avatarSize}, {thumb, avatarThumbnailSize}} {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, thumbnail.Square(img, size.size)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save avatar"})
+			return
+		}
+
+		if err := app.storage.Put(size.key, &buf, "image/png"); err != nil {
+			log.Printf("store avatar %s: %v", size.key, err)
+			app.deleteAvatarFiles(key)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save avatar"})
+			return
+		}
+	}
+
+	previous, err := app.models.Profiles.SetAvatar(user.Id, key)
+	if err != nil {
+		app.deleteAvatarFiles(key)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save avatar"})
+		return
+	}
+	app.deleteAvatarFiles(previous)
+
+	profile := app.getCurrentProfile(c)
+	if profile == nil {
+		return
+	}
+
+	c.JSON(http.StatusOK, app.withAvatarURLs(profile))
+}
+
+// deleteAvatar removes the current user's avatar
+//
+// @Summary Remove avatar
+// @Tags user
+// @Success 204 "Avatar removed"
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/profile/avatar [delete]
+func (app *application) deleteAvatar(c *gin.Context) {
+	user := app.GetUserFromContext(c)
+
+	previous, err := app.models.Profiles.SetAvatar(user.Id, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove avatar"})
+		return
+	}
+	app.deleteAvatarFiles(previous)
+
+	c.Status(http.StatusNoContent)
+}
+
+// getCurrentProfile loads the current user's profile. It writes an error
+// response and returns nil if that fails.
+func (app *application) getCurrentProfile(c *gin.Context) *database.Profile {
+	profile, err := app.models.Profiles.Get(app.GetUserFromContext(c).Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve profile"})
+		return nil
+	}
+
+	// 帳號排定刪除期間不提供個人資料
+	if profile == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+		return nil
+	}
+
+	return profile
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type rateWindow struct {
+	count   int
+	resetAt time.Time
+}
+
+// rateLimiter is a fixed-window, in-memory limiter keyed by an arbitrary string.
+type rateLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	window  time.Duration
+	windows map[string]*rateWindow
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:   limit,
+		window:  window,
+		windows: make(map[string]*rateWindow),
+	}
+}
+
+// allow records a hit for key and reports whether it is within the limit.
+// When the limit is exceeded it also returns how long until the window resets.
+func (l *rateLimiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	// 順便清除過期的計數，避免 map 無限制成長
+	for k, w := range l.windows {
+		if now.After(w.resetAt) {
+			delete(l.windows, k)
+		}
+	}
+
+	w, exists := l.windows[key]
+	if !exists {
+		w = &rateWindow{resetAt: now.Add(l.window)}
+		l.windows[key] = w
+	}
+
+	if w.count >= l.limit {
+		return false, w.resetAt.Sub(now)
+	}
+
+	w.count++
+	return true, 0
+}
+
+// RateLimit 依照客戶端 IP 限制請求頻率
+func RateLimit(limit int, window time.Duration) gin.HandlerFunc {
+	limiter := newRateLimiter(limit, window)
+
+	return func(c *gin.Context) {
+		allowed, retryAfter := limiter.allow(c.ClientIP())
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests, please try again later"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getRoles lists roles and their permissions
+//
+// @Summary List roles
+// @Description List every role with the permissions it grants (requires users.manage)
+// @Tags admin
+// @Produce json
+// @Success 200 {array} database.Role
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /admin/roles [get]
+func (app *application) getRoles(c *gin.Context) {
+	roles, err := app.models.Roles.GetAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve roles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, roles)
+}
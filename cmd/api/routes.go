@@ -1,7 +1,9 @@
 package main
 
 import (
+	"event-api-app/internal/storage"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -14,37 +16,184 @@ func (app *application) routes() http.Handler {
 
 	v1 := g.Group("/api/v1")
 	{
-		// Event routes
-		v1.GET("/events", app.getAllEvents)
-		v1.GET("/events/:id", app.getEvent)
-
-		// Attendee routes
-		v1.GET("/events/:id/attendees", app.getAttendeesForEvent)
-		v1.GET("/attendees/:userId/events", app.getEventsByAttendee)
-
 		// User routes
 		v1.POST("/auth/register", app.registerUser)
 
 		// Authentication routes
 		v1.POST("/auth/login", app.login)
+		v1.POST("/auth/login/mfa", app.loginMFA)
+		v1.POST("/auth/refresh", app.refreshToken)
+
+		// Magic link routes
+		v1.POST("/auth/magic-link", RateLimit(3, 15*time.Minute), app.requestMagicLink)
+		v1.GET("/auth/magic-link/verify", app.confirmMagicLink)
+		v1.POST("/auth/magic-link/verify", app.loginMagicLink)
+
+		// External identity provider routes
+		v1.GET("/auth/oidc/:provider/login", app.oidcLogin)
+		v1.GET("/auth/oidc/:provider/callback", app.oidcCallback)
+
+		// Passkey login routes
+		v1.POST("/auth/webauthn/login/begin", RateLimit(20, 15*time.Minute), app.beginPasskeyLogin)
+		v1.POST("/auth/webauthn/login/finish", app.finishPasskeyLogin)
+
+		// Email verification routes
+		v1.GET("/auth/verify", app.verifyEmail)
+		v1.POST("/auth/verify", app.verifyEmail)
+		v1.POST("/auth/verify/resend", RateLimit(3, 15*time.Minute), app.resendVerification)
+
+		// Password reset routes
+		v1.POST("/auth/password/forgot", RateLimit(3, 15*time.Minute), app.forgotPassword)
+		v1.POST("/auth/password/reset", RateLimit(10, 15*time.Minute), app.resetPassword)
+
+		// Email change confirmation routes
+		v1.GET("/auth/email/confirm", app.confirmEmailChange)
+		v1.POST("/auth/email/confirm", app.confirmEmailChange)
+	}
+
+	// 公開的活動查詢：可匿名存取，登入後可看到所屬組織的私人活動
+	tenantGroup := v1.Group("/")
+	tenantGroup.Use(app.OptionalAuth(), app.ResolveTenant())
+	{
+		// Event routes
+		tenantGroup.GET("/events", app.RequireScope(scopeEventsRead), app.getAllEvents)
+		tenantGroup.GET("/events/:id", app.RequireScope(scopeEventsRead), app.getEvent)
+
+		// Attendee routes
+		tenantGroup.GET("/events/:id/attendees", app.RequireScope(scopeAttendeesRead), app.getAttendeesForEvent)
+		tenantGroup.GET("/attendees/:userId/events", app.RequireScope(scopeAttendeesRead), app.getEventsByAttendee)
 	}
 
+	// Public profile route
+	v1.GET("/users/:id", app.getUserProfile)
+
 	authGroup := v1.Group("/")
-	authGroup.Use(app.AuthMiddleware())
+	authGroup.Use(app.AuthMiddleware(), app.ResolveTenant())
 	{
 		// 受保護的路由
 
 		// Event routes
-		authGroup.POST("/events", RequireVerifiedUser(), app.createEvent)
-		authGroup.PUT("/events/:id", RequireVerifiedUser(), app.updateEvent)
-		authGroup.DELETE("/events/:id", RequireVerifiedUser(), app.deleteEvent)
+		authGroup.POST("/events", RequireVerifiedUser(), app.RequireScope(scopeEventsWrite), app.RequirePermission("events.create"), app.createEvent)
+		authGroup.PUT("/events/:id", RequireVerifiedUser(), app.RequireScope(scopeEventsWrite), app.updateEvent)
+		authGroup.DELETE("/events/:id", RequireVerifiedUser(), app.RequireScope(scopeEventsWrite), app.deleteEvent)
+
+		// Event host routes
+		authGroup.GET("/events/:id/hosts", app.RequireScope(scopeEventsRead), app.getEventHosts)
+		authGroup.POST("/events/:id/hosts", RequireVerifiedUser(), app.RequireScope(scopeEventsWrite), app.inviteEventHost)
+		authGroup.DELETE("/events/:id/hosts/:userId", RequireVerifiedUser(), app.RequireScope(scopeEventsWrite), app.removeEventHost)
+		authGroup.POST("/events/:id/transfer", RequireVerifiedUser(), app.RequireScope(scopeEventsWrite), app.transferEventOwnership)
 
 		// Attendee routes
-		authGroup.POST("/events/:id/attendees/:userId", RequireVerifiedUser(), app.addAttendeeToEvent)
-		authGroup.DELETE("/events/:id/attendees/:userId", RequireVerifiedUser(), app.deleteAttendeeFromEvent)
+		authGroup.POST("/events/:id/attendees/:userId", RequireVerifiedUser(), app.RequireScope(scopeAttendeesWrite), app.addAttendeeToEvent)
+		authGroup.DELETE("/events/:id/attendees/:userId", RequireVerifiedUser(), app.RequireScope(scopeAttendeesWrite), app.deleteAttendeeFromEvent)
+	}
 
+	// 帳號管理路由不接受 API key
+	accountGroup := v1.Group("/auth")
+	accountGroup.Use(app.AuthMiddleware(), app.RequireUserSession())
+	{
 		// User update route
-		authGroup.PUT("/auth/user", app.updateUser)
+		accountGroup.PUT("/user", app.updateUser)
+
+		// Profile routes
+		accountGroup.GET("/profile", app.getMyProfile)
+		accountGroup.PUT("/profile", app.updateProfile)
+		accountGroup.PUT("/profile/avatar", app.uploadAvatar)
+		accountGroup.DELETE("/profile/avatar", app.deleteAvatar)
+
+		// Account deletion and data export routes
+		accountGroup.GET("/user/export", app.exportAccount)
+		accountGroup.DELETE("/user", app.deleteAccount)
+		accountGroup.POST("/user/restore", app.restoreAccount)
+
+		// Logout and session routes
+		accountGroup.POST("/logout", app.logout)
+		accountGroup.GET("/sessions", app.getSessions)
+		accountGroup.DELETE("/sessions", app.deleteAllSessions)
+		accountGroup.DELETE("/sessions/:id", app.deleteSession)
+
+		// MFA routes
+		accountGroup.POST("/mfa/totp", app.enrollTOTP)
+		accountGroup.GET("/mfa/totp/qr", app.getTOTPQRCode)
+		accountGroup.POST("/mfa/totp/confirm", app.confirmTOTP)
+
+		// Passkey routes
+		accountGroup.POST("/webauthn/register/begin", app.beginPasskeyRegistration)
+		accountGroup.POST("/webauthn/register/finish", app.finishPasskeyRegistration)
+		accountGroup.GET("/webauthn/credentials", app.getPasskeys)
+		accountGroup.PUT("/webauthn/credentials/:id", app.renamePasskey)
+		accountGroup.DELETE("/webauthn/credentials/:id", app.deletePasskey)
+
+		// API key routes
+		accountGroup.GET("/api-keys", app.getAPIKeys)
+		accountGroup.POST("/api-keys", app.createAPIKey)
+		accountGroup.DELETE("/api-keys/:id", app.deleteAPIKey)
+	}
+
+	meGroup := v1.Group("/me")
+	meGroup.Use(app.AuthMiddleware(), app.RequireScope(scopeEventsRead))
+	{
+		meGroup.GET("", app.getMe)
+		meGroup.GET("/events", app.getMyEvents)
+		meGroup.GET("/calendar", app.getMyCalendar)
+	}
+
+	orgGroup := v1.Group("/orgs")
+	orgGroup.Use(app.AuthMiddleware(), app.RequireUserSession())
+	{
+		orgGroup.GET("", app.getOrganizations)
+		orgGroup.POST("", app.createOrganization)
+		orgGroup.GET("/:org", app.getOrganization)
+		orgGroup.PUT("/:org", app.updateOrganization)
+		orgGroup.DELETE("/:org", app.deleteOrganization)
+		orgGroup.GET("/:org/members", app.getOrganizationMembers)
+		orgGroup.POST("/:org/members", app.addOrganizationMember)
+		orgGroup.PUT("/:org/members/:userId", app.setOrganizationMemberRole)
+		orgGroup.DELETE("/:org/members/:userId", app.removeOrganizationMember)
+	}
+
+	adminGroup := v1.Group("/admin")
+	adminGroup.Use(app.AuthMiddleware(), app.RequireUserSession(), app.RequirePermission("users.manage"))
+	{
+		adminGroup.GET("/roles", app.getRoles)
+		adminGroup.GET("/users", app.listUsers)
+		adminGroup.GET("/users/:id", app.getUser)
+		adminGroup.DELETE("/users/:id", app.deleteUser)
+		adminGroup.PUT("/users/:id/role", app.setUserRole)
+		adminGroup.POST("/users/:id/verify", app.forceVerifyUser)
+		adminGroup.POST("/users/:id/disable", app.disableUser)
+		adminGroup.POST("/users/:id/enable", app.enableUser)
+		adminGroup.GET("/lockouts", app.getLockouts)
+		adminGroup.POST("/users/:id/unlock", app.unlockUser)
+		adminGroup.DELETE("/users/:id/mfa", app.resetUserMFA)
+	}
+
+	// SCIM 2.0 provisioning routes（以 SCIM_TOKEN 驗證，不使用用戶 JWT）
+	scimGroup := g.Group("/scim/v2")
+	scimGroup.Use(app.RequireSCIMToken())
+	{
+		scimGroup.GET("/ServiceProviderConfig", app.getSCIMServiceProviderConfig)
+
+		scimGroup.GET("/Users", app.listSCIMUsers)
+		scimGroup.POST("/Users", app.createSCIMUser)
+		scimGroup.GET("/Users/:id", app.getSCIMUser)
+		scimGroup.PUT("/Users/:id", app.replaceSCIMUser)
+		scimGroup.PATCH("/Users/:id", app.patchSCIMUser)
+		scimGroup.DELETE("/Users/:id", app.deleteSCIMUser)
+
+		scimGroup.GET("/Groups", app.listSCIMGroups)
+		scimGroup.POST("/Groups", app.createSCIMGroup)
+		scimGroup.GET("/Groups/:id", app.getSCIMGroup)
+		scimGroup.PUT("/Groups/:id", app.replaceSCIMGroup)
+		scimGroup.PATCH("/Groups/:id", app.patchSCIMGroup)
+		scimGroup.DELETE("/Groups/:id", app.deleteSCIMGroup)
+	}
+
+	g.GET("/.well-known/jwks.json", app.jwks)
+
+	// 本機儲存的上傳檔案（頭像）由應用程式直接提供
+	if local, ok := app.storage.(*storage.Local); ok {
+		g.Static(uploadsPath, local.Dir)
 	}
 
 	g.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, ginSwagger.URL("http://localhost:8080/swagger/doc.json")))
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"event-api-app/internal/scim"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	scimDefaultCount = 100
+	scimMaxCount     = 200
+)
+
+type scimServiceProviderConfig struct {
+	Schemas               []string             `json:"schemas"`
+	Patch                 scimSupported        `json:"patch"`
+	Bulk                  scimSupported        `json:"bulk"`
+	Filter                scimFilterSupport    `json:"filter"`
+	ChangePassword        scimSupported        `json:"changePassword"`
+	Sort                  scimSupported        `json:"sort"`
+	ETag                  scimSupported        `json:"etag"`
+	AuthenticationSchemes []scimAuthScheme     `json:"authenticationSchemes"`
+	Meta                  scimResourceTypeMeta `json:"meta"`
+}
+
+type scimSupported struct {
+	Supported bool `json:"supported"`
+}
+
+type scimFilterSupport struct {
+	Supported  bool `json:"supported"`
+	MaxResults int  `json:"maxResults"`
+}
+
+type scimAuthScheme struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Primary     bool   `json:"primary"`
+}
+
+type scimResourceTypeMeta struct {
+	ResourceType string `json:"resourceType"`
+}
+
+// scimMember references a user in a group, or a group in a user.
+type scimMember struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// RequireSCIMToken 驗證 SCIM 用戶端的 bearer token（SCIM_TOKEN）；未設定時 SCIM 端點一律停用
+func (app *application) RequireSCIMToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if app.scimTokenHash == nil {
+			scimError(c, &scim.Error{Status: http.StatusNotFound, Detail: "SCIM provisioning is not enabled"})
+			c.Abort()
+			return
+		}
+
+		token, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		hash := sha256.Sum256([]byte(token))
+
+		// 比較雜湊而非原文，避免比較時間洩漏 token 長度
+		if !found || subtle.ConstantTimeCompare(hash[:], app.scimTokenHash) != 1 {
+			c.Header("WWW-Authenticate", `Bearer realm="scim"`)
+			scimError(c, &scim.Error{Status: http.StatusUnauthorized, Detail: "Invalid bearer token"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// newSCIMTokenHash 回傳 SCIM_TOKEN 的 SHA-256；未設定時回傳 nil 以停用 SCIM
+func newSCIMTokenHash(token string) []byte {
+	if token == "" {
+		return nil
+	}
+	hash := sha256.Sum256([]byte(token))
+	return hash[:]
+}
+
+// scimJSON writes body with the SCIM media type.
+func scimJSON(c *gin.Context, status int, body any) {
+	c.Header("Content-Type", scim.ContentType)
+	c.JSON(status, body)
+}
+
+// scimError writes err as a SCIM error response. Errors that are not SCIM
+// errors are logged and reported as 500.
+func scimError(c *gin.Context, err error) {
+	var scimErr *scim.Error
+	if !errors.As(err, &scimErr) {
+		log.Printf("scim %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		scimErr = &scim.Error{Status: http.StatusInternalServerError, Detail: "Something went wrong"}
+	}
+
+	scimJSON(c, scimErr.Status, scimErr)
+}
+
+// scimPage reads the 1-based startIndex and count query parameters.
+func scimPage(c *gin.Context) (startIndex, count int) {
+	startIndex, err := strconv.Atoi(c.Query("startIndex"))
+	if err != nil || startIndex < 1 {
+		startIndex = 1
+	}
+
+	count, err = strconv.Atoi(c.DefaultQuery("count", strconv.Itoa(scimDefaultCount)))
+	if err != nil || count > scimMaxCount {
+		count = scimMaxCount
+	}
+	if count < 0 {
+		count = 0
+	}
+
+	return startIndex, count
+}
+
+// scimLocation returns the URL of a SCIM resource.
+func (app *application) scimLocation(resourceType, id string) string {
+	return app.baseURL + "/scim/v2/" + resourceType + "/" + id
+}
+
+// getSCIMServiceProviderConfig describes the supported SCIM features
+//
+// @Summary SCIM service provider configuration
+// @Description Describe the SCIM 2.0 features this server supports
+// @Tags scim
+// @Produce json
+// @Success 200 {object} scimServiceProviderConfig
+// @Failure 401 {object} map[string]string
+// @Security BearerAuth
+// @Router /scim/v2/ServiceProviderConfig [get]
+func (app *application) getSCIMServiceProviderConfig(c *gin.Context) {
+	scimJSON(c, http.StatusOK, scimServiceProviderConfig{
+		Schemas:        []string{scim.SchemaServiceProviderConfig},
+		Patch:          scimSupported{Supported: true},
+		Bulk:           scimSupported{Supported: false},
+		Filter:         scimFilterSupport{Supported: true, MaxResults: scimMaxCount},
+		ChangePassword: scimSupported{Supported: true},
+		Sort:           scimSupported{Supported: false},
+		ETag:           scimSupported{Supported: false},
+		AuthenticationSchemes: []scimAuthScheme{{
+			Type:        "oauthbearertoken",
+			Name:        "OAuth Bearer Token",
+			Description: "The token configured in SCIM_TOKEN",
+			Primary:     true,
+		}},
+		Meta: scimResourceTypeMeta{ResourceType: "ServiceProviderConfig"},
+	})
+}
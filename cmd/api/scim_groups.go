@@ -0,0 +1,523 @@
+package main
+
+import (
+	"encoding/json"
+	"event-api-app/internal/database"
+	"event-api-app/internal/scim"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SCIM groups are the roles. A user holds exactly one role, so adding a user
+// to a group moves them out of their previous group, and removing a user
+// from a group gives them the default role.
+
+type scimGroup struct {
+	Schemas     []string      `json:"schemas"`
+	Id          string        `json:"id"`
+	DisplayName string        `json:"displayName"`
+	Members     *[]scimMember `json:"members,omitempty"`
+	Meta        *scim.Meta    `json:"meta,omitempty"`
+}
+
+// scimGroupRequest is the body of PUT and POST /Groups.
+type scimGroupRequest struct {
+	Schemas     []string     `json:"schemas"`
+	DisplayName string       `json:"displayName"`
+	Members     []scimMember `json:"members"`
+}
+
+// toSCIMGroup renders a role as a SCIM Group resource. Members are omitted
+// when nil, for clients that exclude them.
+func (app *application) toSCIMGroup(role *database.Role, members []database.RoleMember) *scimGroup {
+	group := &scimGroup{
+		Schemas:     []string{scim.SchemaGroup},
+		Id:          role.Name,
+		DisplayName: role.Name,
+		Meta: &scim.Meta{
+			ResourceType: "Group",
+			Location:     app.scimLocation("Groups", role.Name),
+		},
+	}
+
+	if members != nil {
+		list := make([]scimMember, len(members))
+		for i, member := range members {
+			id := strconv.Itoa(member.UserId)
+			list[i] = scimMember{Value: id, Display: member.Name, Ref: app.scimLocation("Users", id)}
+		}
+		group.Members = &list
+	}
+
+	return group
+}
+
+// scimExcludesMembers reports whether the client asked to leave members out,
+// which avoids listing every user for the default role.
+func scimExcludesMembers(c *gin.Context) bool {
+	for _, attr := range strings.Split(c.Query("excludedAttributes"), ",") {
+		if strings.EqualFold(strings.TrimSpace(attr), "members") {
+			return true
+		}
+	}
+	return false
+}
+
+// getSCIMGroupParam loads the role named by the :id path parameter, writing a
+// 404 and returning nil if there is none.
+func (app *application) getSCIMGroupParam(c *gin.Context) *database.Role {
+	roles, err := app.models.Roles.GetAll()
+	if err != nil {
+		scimError(c, err)
+		return nil
+	}
+
+	for _, role := range roles {
+		if role.Name == c.Param("id") {
+			return role
+		}
+	}
+
+	scimError(c, &scim.Error{Status: http.StatusNotFound, Detail: "Group not found"})
+	return nil
+}
+
+// listSCIMGroups lists roles as SCIM groups
+//
+// @Summary List SCIM groups
+// @Description List roles as SCIM 2.0 groups, optionally filtered (e.g. displayName eq "admin")
+// @Tags scim
+// @Produce json
+// @Param filter query string false "SCIM filter expression"
+// @Param excludedAttributes query string false "Set to members to leave out the member lists"
+// @Param startIndex query int false "1-based index of the first result" default(1)
+// @Param count query int false "Results per page (max 200)" default(100)
+// @Success 200 {object} scim.ListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Security BearerAuth
+// @Router /scim/v2/Groups [get]
+func (app *application) listSCIMGroups(c *gin.Context) {
+	startIndex, count := scimPage(c)
+
+	var expr scim.Expr
+	if filter := c.Query("filter"); filter != "" {
+		var err error
+		if expr, err = scim.ParseFilter(filter); err != nil {
+			scimError(c, err)
+			return
+		}
+	}
+
+	roles, err := app.models.Roles.GetAll()
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+
+	excludeMembers := scimExcludesMembers(c)
+	groups := []*scimGroup{}
+
+	// 角色數量很少，直接在記憶體中過濾
+	for _, role := range roles {
+		var members []database.RoleMember
+		if !excludeMembers {
+			if members, err = app.models.Roles.GetMembers(role.Name); err != nil {
+				scimError(c, err)
+				return
+			}
+		}
+
+		group := app.toSCIMGroup(role, members)
+		if expr == nil || scim.Match(expr, scimGroupAttrs(group)) {
+			groups = append(groups, group)
+		}
+	}
+
+	total := len(groups)
+	groups = groups[min(startIndex-1, total):min(startIndex-1+count, total)]
+
+	scimJSON(c, http.StatusOK, scim.NewListResponse(groups, len(groups), total, startIndex))
+}
+
+// scimGroupAttrs returns the filterable attributes of a group for scim.Match.
+func scimGroupAttrs(group *scimGroup) map[string]any {
+	attrs := map[string]any{"id": group.Id, "displayname": group.DisplayName}
+
+	if group.Members != nil {
+		members := make([]map[string]any, len(*group.Members))
+		for i, member := range *group.Members {
+			members[i] = map[string]any{"value": member.Value, "display": member.Display}
+		}
+		attrs["members"] = members
+	}
+
+	return attrs
+}
+
+// getSCIMGroup returns one role as a SCIM group
+//
+// @Summary Get SCIM group
+// @Tags scim
+// @Produce json
+// @Param id path string true "Role name"
+// @Param excludedAttributes query string false "Set to members to leave out the member list"
+// @Success 200 {object} scimGroup
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /scim/v2/Groups/{id} [get]
+func (app *application) getSCIMGroup(c *gin.Context) {
+	role := app.getSCIMGroupParam(c)
+	if role == nil {
+		return
+	}
+
+	app.respondSCIMGroup(c, role)
+}
+
+// createSCIMGroup rejects group creation
+//
+// @Summary Create SCIM group
+// @Description Groups are roles and cannot be created through SCIM. An existing role with the same name is reported as a conflict so clients can link to it.
+// @Tags scim
+// @Accept json
+// @Produce json
+// @Param group body scimGroupRequest true "SCIM Group resource"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 501 {object} map[string]string
+// @Security BearerAuth
+// @Router /scim/v2/Groups [post]
+func (app *application) createSCIMGroup(c *gin.Context) {
+	var req scimGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		scimError(c, &scim.Error{Status: http.StatusBadRequest, Type: scim.ErrorInvalidSyntax, Detail: err.Error()})
+		return
+	}
+
+	exists, err := app.models.Roles.Exists(req.DisplayName)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+
+	if exists {
+		scimError(c, &scim.Error{Status: http.StatusConflict, Type: scim.ErrorUniqueness, Detail: "Group " + req.DisplayName + " already exists"})
+		return
+	}
+
+	scimError(c, &scim.Error{Status: http.StatusNotImplemented, Detail: "Groups are roles; create the role first"})
+}
+
+// replaceSCIMGroup replaces a group's members
+//
+// @Summary Replace SCIM group
+// @Description Make exactly the listed users hold the role. Users no longer listed get the default role.
+// @Tags scim
+// @Accept json
+// @Produce json
+// @Param id path string true "Role name"
+// @Param group body scimGroupRequest true "SCIM Group resource"
+// @Success 200 {object} scimGroup
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /scim/v2/Groups/{id} [put]
+func (app *application) replaceSCIMGroup(c *gin.Context) {
+	role := app.getSCIMGroupParam(c)
+	if role == nil {
+		return
+	}
+
+	var req scimGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		scimError(c, &scim.Error{Status: http.StatusBadRequest, Type: scim.ErrorInvalidSyntax, Detail: err.Error()})
+		return
+	}
+
+	if req.DisplayName != "" && req.DisplayName != role.Name {
+		scimError(c, &scim.Error{Status: http.StatusBadRequest, Type: scim.ErrorMutability, Detail: "displayName is the role name and cannot be changed"})
+		return
+	}
+
+	members, err := app.loadSCIMGroupMembers(role)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+
+	if err := app.replaceSCIMGroupMembers(members, req.Members); err != nil {
+		scimError(c, err)
+		return
+	}
+
+	if err := app.saveSCIMGroupMembers(members); err != nil {
+		scimError(c, err)
+		return
+	}
+
+	app.respondSCIMGroup(c, role)
+}
+
+// patchSCIMGroup adds or removes group members
+//
+// @Summary Patch SCIM group
+// @Description Add, replace or remove members, e.g. {"op":"remove","path":"members[value eq \"42\"]"}. Removed users get the default role.
+// @Tags scim
+// @Accept json
+// @Produce json
+// @Param id path string true "Role name"
+// @Param request body scim.PatchRequest true "SCIM PatchOp message"
+// @Success 200 {object} scimGroup
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /scim/v2/Groups/{id} [patch]
+func (app *application) patchSCIMGroup(c *gin.Context) {
+	role := app.getSCIMGroupParam(c)
+	if role == nil {
+		return
+	}
+
+	var req scim.PatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		scimError(c, &scim.Error{Status: http.StatusBadRequest, Type: scim.ErrorInvalidSyntax, Detail: err.Error()})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		scimError(c, err)
+		return
+	}
+
+	members, err := app.loadSCIMGroupMembers(role)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+
+	// 所有操作都成功後才一次寫回，任一操作失敗時不會只改到部分用戶的角色
+	for _, op := range req.Operations {
+		if err := app.patchSCIMGroupOp(members, op); err != nil {
+			scimError(c, err)
+			return
+		}
+	}
+
+	if err := app.saveSCIMGroupMembers(members); err != nil {
+		scimError(c, err)
+		return
+	}
+
+	app.respondSCIMGroup(c, role)
+}
+
+// deleteSCIMGroup rejects group deletion
+//
+// @Summary Delete SCIM group
+// @Description Groups are roles and cannot be deleted through SCIM.
+// @Tags scim
+// @Param id path string true "Role name"
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 501 {object} map[string]string
+// @Security BearerAuth
+// @Router /scim/v2/Groups/{id} [delete]
+func (app *application) deleteSCIMGroup(c *gin.Context) {
+	if role := app.getSCIMGroupParam(c); role == nil {
+		return
+	}
+
+	scimError(c, &scim.Error{Status: http.StatusNotImplemented, Detail: "Groups are roles and cannot be deleted through SCIM"})
+}
+
+// respondSCIMGroup writes the role as a SCIM group with its current members.
+func (app *application) respondSCIMGroup(c *gin.Context, role *database.Role) {
+	var members []database.RoleMember
+	if !scimExcludesMembers(c) {
+		var err error
+		if members, err = app.models.Roles.GetMembers(role.Name); err != nil {
+			scimError(c, err)
+			return
+		}
+	}
+
+	scimJSON(c, http.StatusOK, app.toSCIMGroup(role, members))
+}
+
+// scimGroupMembers is the membership of a group while a request changes it.
+// The changes are checked in memory first and then written in one
+// transaction by saveSCIMGroupMembers, so a failing operation leaves every
+// user's role as it was.
+type scimGroupMembers struct {
+	role    *database.Role
+	initial map[int]bool
+	members map[int]database.RoleMember
+}
+
+func (app *application) loadSCIMGroupMembers(role *database.Role) (*scimGroupMembers, error) {
+	members, err := app.models.Roles.GetMembers(role.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &scimGroupMembers{role: role, initial: map[int]bool{}, members: map[int]database.RoleMember{}}
+	for _, member := range members {
+		m.initial[member.UserId] = true
+		m.members[member.UserId] = member
+	}
+
+	return m, nil
+}
+
+// remove moves the user out of the group. Members of the default role stay
+// where they are.
+func (m *scimGroupMembers) remove(userId int) {
+	if !m.role.IsDefault {
+		delete(m.members, userId)
+	}
+}
+
+// saveSCIMGroupMembers gives the role to the users added to the group and the
+// default role to the users removed from it.
+func (app *application) saveSCIMGroupMembers(m *scimGroupMembers) error {
+	roles := map[int]string{}
+
+	for id := range m.members {
+		if !m.initial[id] {
+			roles[id] = m.role.Name
+		}
+	}
+
+	var defaultRole string
+	for id := range m.initial {
+		if _, ok := m.members[id]; ok {
+			continue
+		}
+
+		if defaultRole == "" {
+			var err error
+			if defaultRole, err = app.models.Roles.DefaultName(); err != nil {
+				return err
+			}
+		}
+		roles[id] = defaultRole
+	}
+
+	if len(roles) == 0 {
+		return nil
+	}
+
+	return app.models.Users.SetRoles(roles)
+}
+
+func (app *application) patchSCIMGroupOp(m *scimGroupMembers, op scim.PatchOperation) error {
+	if op.Path == "" {
+		var attrs map[string]json.RawMessage
+		if err := json.Unmarshal(op.Value, &attrs); err != nil {
+			return &scim.Error{Status: http.StatusBadRequest, Type: scim.ErrorInvalidValue, Detail: "value must be an object when path is omitted"}
+		}
+
+		for name, value := range attrs {
+			if err := app.patchSCIMGroupOp(m, scim.PatchOperation{Op: op.Op, Path: name, Value: value}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	path, err := scim.ParsePath(op.Path)
+	if err != nil {
+		return err
+	}
+
+	switch path.Attr {
+	case "displayname":
+		name, err := scim.DecodeString(op.Value)
+		if err != nil || op.Op == "remove" || name != m.role.Name {
+			return &scim.Error{Status: http.StatusBadRequest, Type: scim.ErrorMutability, Detail: "displayName is the role name and cannot be changed"}
+		}
+		return nil
+
+	case "members":
+		return app.patchSCIMGroupMembers(m, op, path)
+	}
+
+	return &scim.Error{Status: http.StatusBadRequest, Type: scim.ErrorInvalidPath, Detail: "unsupported path " + op.Path}
+}
+
+func (app *application) patchSCIMGroupMembers(m *scimGroupMembers, op scim.PatchOperation, path *scim.Path) error {
+	if op.Op == "remove" {
+		for id, member := range m.members {
+			attrs := map[string]any{"value": strconv.Itoa(id), "display": member.Name}
+			if path.Filter == nil || scim.Match(path.Filter, attrs) {
+				m.remove(id)
+			}
+		}
+		return nil
+	}
+
+	var list []scimMember
+	if err := json.Unmarshal(op.Value, &list); err != nil {
+		return &scim.Error{Status: http.StatusBadRequest, Type: scim.ErrorInvalidValue, Detail: "members must be a list"}
+	}
+
+	if op.Op == "replace" {
+		return app.replaceSCIMGroupMembers(m, list)
+	}
+
+	return app.addSCIMGroupMembers(m, list)
+}
+
+// addSCIMGroupMembers adds each listed user to the group.
+func (app *application) addSCIMGroupMembers(m *scimGroupMembers, list []scimMember) error {
+	for _, member := range list {
+		id, err := strconv.Atoi(member.Value)
+		if err != nil {
+			return &scim.Error{Status: http.StatusBadRequest, Type: scim.ErrorInvalidValue, Detail: "unknown member " + member.Value}
+		}
+
+		if _, ok := m.members[id]; ok {
+			continue
+		}
+
+		user, err := app.models.Users.Get(id)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return &scim.Error{Status: http.StatusBadRequest, Type: scim.ErrorInvalidValue, Detail: "unknown member " + member.Value}
+		}
+
+		m.members[id] = database.RoleMember{UserId: id, Name: user.Name}
+	}
+
+	return nil
+}
+
+// replaceSCIMGroupMembers makes exactly the listed users members of the group.
+func (app *application) replaceSCIMGroupMembers(m *scimGroupMembers, list []scimMember) error {
+	if err := app.addSCIMGroupMembers(m, list); err != nil {
+		return err
+	}
+
+	keep := map[string]bool{}
+	for _, member := range list {
+		keep[member.Value] = true
+	}
+
+	for id := range m.members {
+		if !keep[strconv.Itoa(id)] {
+			m.remove(id)
+		}
+	}
+
+	return nil
+}
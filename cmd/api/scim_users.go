@@ -0,0 +1,605 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"event-api-app/internal/database"
+	"event-api-app/internal/pwpolicy"
+	"event-api-app/internal/scim"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// scimUserColumns maps the filterable User attributes onto the users table.
+// Every user has exactly one email, of type "work", which is also the userName.
+var scimUserColumns = scim.Columns{
+	"id":             {SQL: "id", Type: scim.Integer},
+	"externalid":     {SQL: "external_id", Type: scim.ExactString},
+	"username":       {SQL: "email", Type: scim.String},
+	"displayname":    {SQL: "name", Type: scim.String},
+	"name.formatted": {SQL: "name", Type: scim.String},
+	"emails":         {SQL: "email", Type: scim.String},
+	"emails.value":   {SQL: "email", Type: scim.String},
+	"emails.type":    {SQL: "'work'", Type: scim.String},
+	"emails.primary": {SQL: "true", Type: scim.Boolean},
+	"active":         {SQL: "(disabled_at IS NULL)", Type: scim.Boolean},
+	"groups":         {SQL: "role", Type: scim.ExactString},
+	"groups.value":   {SQL: "role", Type: scim.ExactString},
+	"meta.created":   {SQL: "created_at", Type: scim.DateTime},
+}
+
+type scimUser struct {
+	Schemas     []string     `json:"schemas"`
+	Id          string       `json:"id,omitempty"`
+	ExternalId  string       `json:"externalId,omitempty"`
+	UserName    string       `json:"userName"`
+	Name        *scimName    `json:"name,omitempty"`
+	DisplayName string       `json:"displayName,omitempty"`
+	Emails      []scimEmail  `json:"emails,omitempty"`
+	Active      *bool        `json:"active,omitempty"`
+	Password    string       `json:"password,omitempty"`
+	Groups      []scimMember `json:"groups,omitempty"`
+	Meta        *scim.Meta   `json:"meta,omitempty"`
+}
+
+type scimName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type scimEmail struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// toSCIMUser renders a user as a SCIM User resource. Groups are the user's role.
+func (app *application) toSCIMUser(user *database.User) *scimUser {
+	id := strconv.Itoa(user.Id)
+	active := !user.IsDisabled()
+	created := user.CreatedAt
+
+	return &scimUser{
+		Schemas:     []string{scim.SchemaUser},
+		Id:          id,
+		ExternalId:  user.ExternalId,
+		UserName:    user.Email,
+		Name:        &scimName{Formatted: user.Name},
+		DisplayName: user.Name,
+		Emails:      []scimEmail{{Value: user.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Groups:      []scimMember{{Value: user.Role, Display: user.Role, Ref: app.scimLocation("Groups", user.Role)}},
+		Meta: &scim.Meta{
+			ResourceType: "User",
+			Created:      &created,
+			Location:     app.scimLocation("Users", id),
+		},
+	}
+}
+
+// firstChanged returns the first non-empty candidate that differs from
+// current, or current if there is none. SCIM carries the same value in
+// several attributes (userName and emails, displayName and name), and the
+// client only updates some of them.
+func firstChanged(current string, candidates ...string) string {
+	for _, candidate := range candidates {
+		if candidate = strings.TrimSpace(candidate); candidate != "" && candidate != current {
+			return candidate
+		}
+	}
+	return current
+}
+
+// applyTo copies the resource's email, name and external ID onto user.
+func (r *scimUser) applyTo(user *database.User) error {
+	var primaryEmail string
+	for _, email := range r.Emails {
+		if email.Primary || primaryEmail == "" {
+			primaryEmail = email.Value
+		}
+	}
+
+	email := firstChanged(user.Email, r.UserName, primaryEmail)
+	if _, err := mail.ParseAddress(email); err != nil || email != strings.TrimSpace(email) {
+		return &scim.Error{Status: http.StatusBadRequest, Type: scim.ErrorInvalidValue, Detail: "userName must be an email address"}
+	}
+
+	var formatted, joined string
+	if r.Name != nil {
+		formatted = r.Name.Formatted
+		joined = strings.TrimSpace(r.Name.GivenName + " " + r.Name.FamilyName)
+	}
+
+	user.Email = email
+	user.Name = firstChanged(user.Name, r.DisplayName, formatted, joined)
+	user.ExternalId = r.ExternalId
+
+	if user.Name == "" {
+		user.Name, _, _ = strings.Cut(email, "@")
+	}
+
+	return nil
+}
+
+// checkSCIMPassword applies the password policy to a password set by the client.
+func (app *application) checkSCIMPassword(password string, user *database.User) error {
+	err := app.passwordPolicy.Check(password, user.Email, user.Name)
+
+	var violations pwpolicy.Violations
+	if errors.As(err, &violations) {
+		return &scim.Error{Status: http.StatusBadRequest, Type: scim.ErrorInvalidValue, Detail: violations.Error()}
+	}
+	return err
+}
+
+// saveSCIMUser stores the resource's attributes for an existing user in one
+// transaction: email, name and external ID, the password if one was given,
+// and the active flag. Setting a password or deactivating the account signs
+// it out everywhere, and a new password also revokes API keys and
+// invalidates reset links.
+func (app *application) saveSCIMUser(user *database.User, r *scimUser) error {
+	if err := r.applyTo(user); err != nil {
+		return err
+	}
+
+	var update database.ProvisionedUpdate
+
+	if r.Password != "" {
+		if err := app.checkSCIMPassword(r.Password, user); err != nil {
+			return err
+		}
+
+		hash, err := app.passwords.Hash(r.Password)
+		if err != nil {
+			return err
+		}
+		update.PasswordHash = hash
+	}
+
+	if r.Active != nil && *r.Active == user.IsDisabled() {
+		disabled := !*r.Active
+		update.Disabled = &disabled
+	}
+
+	if err := app.models.Users.SaveProvisioned(user, update); err != nil {
+		if errors.Is(err, database.ErrDuplicateEmail) {
+			return &scim.Error{Status: http.StatusConflict, Type: scim.ErrorUniqueness, Detail: "userName is already in use"}
+		}
+		if errors.Is(err, database.ErrDuplicateExternalId) {
+			return &scim.Error{Status: http.StatusConflict, Type: scim.ErrorUniqueness, Detail: "externalId is already in use"}
+		}
+		return err
+	}
+
+	return nil
+}
+
+// getSCIMUserParam loads the user named by the :id path parameter, writing a
+// 404 and returning nil if there is none.
+func (app *application) getSCIMUserParam(c *gin.Context) *database.User {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		scimError(c, &scim.Error{Status: http.StatusNotFound, Detail: "User not found"})
+		return nil
+	}
+
+	user, err := app.models.Users.Get(id)
+	if err != nil {
+		scimError(c, err)
+		return nil
+	}
+
+	if user == nil {
+		scimError(c, &scim.Error{Status: http.StatusNotFound, Detail: "User not found"})
+		return nil
+	}
+
+	return user
+}
+
+// listSCIMUsers lists users for a provisioning client
+//
+// @Summary List SCIM users
+// @Description List users as SCIM 2.0 resources, optionally filtered (e.g. userName eq "a@example.com")
+// @Tags scim
+// @Produce json
+// @Param filter query string false "SCIM filter expression"
+// @Param startIndex query int false "1-based index of the first result" default(1)
+// @Param count query int false "Results per page (max 200)" default(100)
+// @Success 200 {object} scim.ListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Security BearerAuth
+// @Router /scim/v2/Users [get]
+func (app *application) listSCIMUsers(c *gin.Context) {
+	startIndex, count := scimPage(c)
+
+	condition, args := "true", []any{}
+
+	if filter := c.Query("filter"); filter != "" {
+		expr, err := scim.ParseFilter(filter)
+		if err != nil {
+			scimError(c, err)
+			return
+		}
+
+		condition, args, err = scim.ToSQL(expr, scimUserColumns, 1)
+		if err != nil {
+			scimError(c, err)
+			return
+		}
+	}
+
+	users, total, err := app.models.Users.ListMatching(condition, args, startIndex-1, count)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+
+	resources := make([]*scimUser, len(users))
+	for i, user := range users {
+		resources[i] = app.toSCIMUser(user)
+	}
+
+	scimJSON(c, http.StatusOK, scim.NewListResponse(resources, len(resources), total, startIndex))
+}
+
+// getSCIMUser returns one user for a provisioning client
+//
+// @Summary Get SCIM user
+// @Tags scim
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} scimUser
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /scim/v2/Users/{id} [get]
+func (app *application) getSCIMUser(c *gin.Context) {
+	user := app.getSCIMUserParam(c)
+	if user == nil {
+		return
+	}
+
+	scimJSON(c, http.StatusOK, app.toSCIMUser(user))
+}
+
+// createSCIMUser provisions an account
+//
+// @Summary Create SCIM user
+// @Description Create a verified account. userName must be the email address. Without a password the account can only sign in through SSO, a magic link or a password reset.
+// @Tags scim
+// @Accept json
+// @Produce json
+// @Param user body scimUser true "SCIM User resource"
+// @Success 201 {object} scimUser
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /scim/v2/Users [post]
+func (app *application) createSCIMUser(c *gin.Context) {
+	var r scimUser
+	if err := c.ShouldBindJSON(&r); err != nil {
+		scimError(c, &scim.Error{Status: http.StatusBadRequest, Type: scim.ErrorInvalidSyntax, Detail: err.Error()})
+		return
+	}
+
+	// 先在空白用戶上套用屬性以驗證 email，再檢查是否已存在
+	var draft database.User
+	if err := r.applyTo(&draft); err != nil {
+		scimError(c, err)
+		return
+	}
+
+	existing, err := app.models.Users.GetByEmail(draft.Email)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+	if existing != nil {
+		scimError(c, &scim.Error{Status: http.StatusConflict, Type: scim.ErrorUniqueness, Detail: "userName is already in use"})
+		return
+	}
+
+	if r.Password != "" {
+		if err := app.checkSCIMPassword(r.Password, &draft); err != nil {
+			scimError(c, err)
+			return
+		}
+	}
+
+	user, err := app.provisionUser(draft.Email, draft.Name)
+	if err != nil {
+		scimError(c, err)
+		return
+	}
+
+	if err := app.saveSCIMUser(user, &r); err != nil {
+		// 外部識別碼衝突等錯誤時不留下半建立的帳號
+		if deleteErr := app.models.Users.Delete(user.Id); deleteErr != nil {
+			scimError(c, deleteErr)
+			return
+		}
+		scimError(c, err)
+		return
+	}
+
+	app.respondSCIMUser(c, http.StatusCreated, user.Id)
+}
+
+// replaceSCIMUser replaces a user's attributes
+//
+// @Summary Replace SCIM user
+// @Description Replace userName, name, externalId, active and optionally the password. Setting active to false disables the account and signs it out; setting a password signs it out, revokes its API keys and invalidates reset links.
+// @Tags scim
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param user body scimUser true "SCIM User resource"
+// @Success 200 {object} scimUser
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /scim/v2/Users/{id} [put]
+func (app *application) replaceSCIMUser(c *gin.Context) {
+	user := app.getSCIMUserParam(c)
+	if user == nil {
+		return
+	}
+
+	var r scimUser
+	if err := c.ShouldBindJSON(&r); err != nil {
+		scimError(c, &scim.Error{Status: http.StatusBadRequest, Type: scim.ErrorInvalidSyntax, Detail: err.Error()})
+		return
+	}
+
+	// 未提供 active 時視為啟用
+	if r.Active == nil {
+		active := true
+		r.Active = &active
+	}
+
+	if err := app.saveSCIMUser(user, &r); err != nil {
+		scimError(c, err)
+		return
+	}
+
+	app.respondSCIMUser(c, http.StatusOK, user.Id)
+}
+
+// patchSCIMUser applies PATCH operations to a user
+//
+// @Summary Patch SCIM user
+// @Description Apply add, replace and remove operations, e.g. {"op":"replace","path":"active","value":false} to deactivate the account.
+// @Tags scim
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body scim.PatchRequest true "SCIM PatchOp message"
+// @Success 200 {object} scimUser
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /scim/v2/Users/{id} [patch]
+func (app *application) patchSCIMUser(c *gin.Context) {
+	user := app.getSCIMUserParam(c)
+	if user == nil {
+		return
+	}
+
+	var req scim.PatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		scimError(c, &scim.Error{Status: http.StatusBadRequest, Type: scim.ErrorInvalidSyntax, Detail: err.Error()})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		scimError(c, err)
+		return
+	}
+
+	// 先把操作套用到目前的資源表示，再整體寫回資料庫
+	r := app.toSCIMUser(user)
+	for _, op := range req.Operations {
+		if err := r.patch(op); err != nil {
+			scimError(c, err)
+			return
+		}
+	}
+
+	if err := app.saveSCIMUser(user, r); err != nil {
+		scimError(c, err)
+		return
+	}
+
+	app.respondSCIMUser(c, http.StatusOK, user.Id)
+}
+
+// deleteSCIMUser deletes a user
+//
+// @Summary Delete SCIM user
+// @Description Delete the account. Events it owned are kept without an owner.
+// @Tags scim
+// @Param id path string true "User ID"
+// @Success 204
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /scim/v2/Users/{id} [delete]
+func (app *application) deleteSCIMUser(c *gin.Context) {
+	user := app.getSCIMUserParam(c)
+	if user == nil {
+		return
+	}
+
+	if err := app.deleteUserAccount(user.Id); err != nil {
+		scimError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// respondSCIMUser reloads the user and writes it as a SCIM resource.
+func (app *application) respondSCIMUser(c *gin.Context, status, id int) {
+	user, err := app.models.Users.Get(id)
+	if err != nil || user == nil {
+		scimError(c, err)
+		return
+	}
+
+	resource := app.toSCIMUser(user)
+	if status == http.StatusCreated {
+		c.Header("Location", resource.Meta.Location)
+	}
+
+	scimJSON(c, status, resource)
+}
+
+// patch applies one validated PATCH operation to the resource. Operations
+// without a path carry an object of attributes, as some clients send them.
+func (r *scimUser) patch(op scim.PatchOperation) error {
+	if op.Path == "" {
+		var attrs map[string]json.RawMessage
+		if err := json.Unmarshal(op.Value, &attrs); err != nil {
+			return &scim.Error{Status: http.StatusBadRequest, Type: scim.ErrorInvalidValue, Detail: "value must be an object when path is omitted"}
+		}
+
+		for name, value := range attrs {
+			if err := r.patch(scim.PatchOperation{Op: op.Op, Path: name, Value: value}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	path, err := scim.ParsePath(op.Path)
+	if err != nil {
+		return err
+	}
+
+	switch path.Attr {
+	case "username":
+		return patchString(op, &r.UserName, "userName")
+
+	case "displayname":
+		return patchString(op, &r.DisplayName, "")
+
+	case "externalid":
+		return patchString(op, &r.ExternalId, "")
+
+	case "password":
+		return patchString(op, &r.Password, "password")
+
+	case "active":
+		if op.Op == "remove" {
+			return &scim.Error{Status: http.StatusBadRequest, Type: scim.ErrorMutability, Detail: "active cannot be removed"}
+		}
+		active, err := scim.DecodeBool(op.Value)
+		if err != nil {
+			return err
+		}
+		r.Active = &active
+		return nil
+
+	case "name":
+		return r.patchName(op, path.SubAttr)
+
+	case "emails":
+		return r.patchEmails(op, path)
+
+	case "groups":
+		return &scim.Error{Status: http.StatusBadRequest, Type: scim.ErrorMutability, Detail: "groups are changed through /Groups"}
+
+	case "id", "meta", "schemas":
+		return &scim.Error{Status: http.StatusBadRequest, Type: scim.ErrorMutability, Detail: path.Attr + " is read-only"}
+	}
+
+	// 不支援的屬性（例如 enterprise 擴充屬性）直接忽略
+	return nil
+}
+
+// patchString sets or clears a string attribute. required names an attribute
+// that cannot be removed.
+func patchString(op scim.PatchOperation, target *string, required string) error {
+	if op.Op == "remove" {
+		if required != "" {
+			return &scim.Error{Status: http.StatusBadRequest, Type: scim.ErrorMutability, Detail: required + " cannot be removed"}
+		}
+		*target = ""
+		return nil
+	}
+
+	value, err := scim.DecodeString(op.Value)
+	if err != nil {
+		return err
+	}
+	*target = value
+	return nil
+}
+
+func (r *scimUser) patchName(op scim.PatchOperation, subAttr string) error {
+	if r.Name == nil {
+		r.Name = &scimName{}
+	}
+
+	switch subAttr {
+	case "":
+		if op.Op == "remove" {
+			r.Name = &scimName{}
+			return nil
+		}
+		var name scimName
+		if err := json.Unmarshal(op.Value, &name); err != nil {
+			return &scim.Error{Status: http.StatusBadRequest, Type: scim.ErrorInvalidValue, Detail: "invalid name"}
+		}
+		r.Name = &name
+		return nil
+	case "formatted":
+		return patchString(op, &r.Name.Formatted, "")
+	case "givenname":
+		return patchString(op, &r.Name.GivenName, "")
+	case "familyname":
+		return patchString(op, &r.Name.FamilyName, "")
+	}
+
+	return nil
+}
+
+// patchEmails handles "emails" with a list of emails, and paths such as
+// emails[type eq "work"].value that address the single email directly.
+func (r *scimUser) patchEmails(op scim.PatchOperation, path *scim.Path) error {
+	if op.Op == "remove" {
+		return &scim.Error{Status: http.StatusBadRequest, Type: scim.ErrorMutability, Detail: "the email cannot be removed"}
+	}
+
+	if path.SubAttr == "value" {
+		value, err := scim.DecodeString(op.Value)
+		if err != nil {
+			return err
+		}
+		r.Emails = []scimEmail{{Value: value, Primary: true}}
+		return nil
+	}
+
+	if path.SubAttr != "" {
+		return nil
+	}
+
+	var emails []scimEmail
+	if err := json.Unmarshal(op.Value, &emails); err != nil {
+		return &scim.Error{Status: http.StatusBadRequest, Type: scim.ErrorInvalidValue, Detail: "emails must be a list"}
+	}
+	r.Emails = emails
+	return nil
+}
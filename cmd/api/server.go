@@ -16,6 +16,8 @@ func (app *application) serve() error {
 		WriteTimeout: 30 * time.Second,
 	}
 
+	go app.purgeDeletedAccounts()
+
 	log.Printf("Starting server on port %d", app.port)
 
 	return server.ListenAndServe()
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"event-api-app/internal/database"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type sessionResponse struct {
+	*database.Session
+	Current bool `json:"current"`
+}
+
+// getSessions lists the devices the current user is signed in on
+//
+// @Summary List sessions
+// @Description List active sessions with user agent, IP and last-seen time. The session making the request is marked as current.
+// @Tags authentication
+// @Produce json
+// @Success 200 {array} sessionResponse
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/sessions [get]
+func (app *application) getSessions(c *gin.Context) {
+	user := app.GetUserFromContext(c)
+	claims := app.GetClaimsFromContext(c)
+
+	sessions, err := app.models.Sessions.GetActiveForUser(user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve sessions"})
+		return
+	}
+
+	response := make([]sessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, sessionResponse{Session: session, Current: session.Id == claims.SessionId})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// deleteSession logs out one of the current user's sessions
+//
+// @Summary Revoke session
+// @Description Log out a device by revoking its session and refresh tokens
+// @Tags authentication
+// @Param id path int true "Session ID"
+// @Success 204 "Session revoked"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/sessions/{id} [delete]
+func (app *application) deleteSession(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	user := app.GetUserFromContext(c)
+
+	found, err := app.models.Sessions.Revoke(id, user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// deleteAllSessions logs the current user out everywhere
+//
+// @Summary Log out everywhere
+// @Description Revoke every session and API key of the current user, including the session making the request
+// @Tags authentication
+// @Success 204 "All sessions revoked"
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/sessions [delete]
+func (app *application) deleteAllSessions(c *gin.Context) {
+	user := app.GetUserFromContext(c)
+
+	if err := app.models.Sessions.RevokeAllForUser(user.Id, 0); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+
+	if err := app.models.APIKeys.DeleteAllForUser(user.Id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API keys"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"event-api-app/internal/env"
+	"event-api-app/internal/storage"
+	"fmt"
+)
+
+// uploadsPath is where the local storage backend's files are served.
+const uploadsPath = "/uploads"
+
+func newStorage(baseURL string) (storage.Storage, error) {
+	switch backend := env.GetEnvString("STORAGE_BACKEND", "local"); backend {
+	case "local":
+		return storage.NewLocal(env.GetEnvString("STORAGE_DIR", "uploads"), baseURL+uploadsPath)
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q", backend)
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"event-api-app/internal/database"
+	"event-api-app/internal/env"
+	"event-api-app/internal/signing"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+
+	// sessionTouchInterval limits how often last_seen_at is written for a session.
+	sessionTouchInterval = time.Minute
+)
+
+// token_use values keep tokens signed with the same keys from being used
+// in place of one another.
+const (
+	tokenUseAccess = "access"
+	tokenUseMFA    = "mfa"
+)
+
+// tokenClaims are the claims carried by every token this API signs.
+type tokenClaims struct {
+	UserId    int    `json:"user_id"`
+	SessionId int    `json:"sid,omitempty"`
+	TokenUse  string `json:"token_use"`
+	jwt.RegisteredClaims
+}
+
+type tokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// newKeySet 依照 JWT_SIGNING_METHOD 載入簽章金鑰：
+// HS256 使用 JWT_SECRET；RS256/EdDSA 從 JWT_KEYS_DIR 載入 PEM 私鑰，
+// 由 JWT_ACTIVE_KID 指定目前用來簽章的金鑰
+func newKeySet() (*signing.KeySet, error) {
+	switch method := env.GetEnvString("JWT_SIGNING_METHOD", "HS256"); method {
+	case "HS256":
+		return signing.NewHMAC(env.GetEnvString("JWT_SECRET", "mysecret")), nil
+	default:
+		return signing.LoadDir(method, env.GetEnvString("JWT_KEYS_DIR", "keys"), env.GetEnvString("JWT_ACTIVE_KID", ""))
+	}
+}
+
+func newTokenId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// signClaims fills in the jti, iat and exp claims and signs the token.
+func (app *application) signClaims(claims tokenClaims, ttl time.Duration) (string, error) {
+	jti, err := newTokenId()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        jti,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	}
+
+	return app.keys.Sign(claims)
+}
+
+// newSignedToken signs a token for the user with the given purpose and lifetime.
+func (app *application) newSignedToken(userId int, use string, ttl time.Duration) (string, error) {
+	return app.signClaims(tokenClaims{UserId: userId, TokenUse: use}, ttl)
+}
+
+// newAccessToken signs a short-lived access token for the user's session.
+func (app *application) newAccessToken(userId, sessionId int) (string, error) {
+	return app.signClaims(tokenClaims{UserId: userId, SessionId: sessionId, TokenUse: tokenUseAccess}, accessTokenTTL)
+}
+
+// parseToken verifies the signature, the exp/iat claims and the purpose of a token.
+func (app *application) parseToken(tokenString, use string) (*tokenClaims, error) {
+	claims := &tokenClaims{}
+
+	_, err := jwt.ParseWithClaims(tokenString, claims, app.keys.Keyfunc,
+		jwt.WithValidMethods([]string{app.keys.Method()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.ID == "" {
+		return nil, jwt.ErrTokenInvalidId
+	}
+
+	if claims.TokenUse != use {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+
+	return claims, nil
+}
+
+// parseAccessToken verifies an access token.
+func (app *application) parseAccessToken(tokenString string) (*tokenClaims, error) {
+	return app.parseToken(tokenString, tokenUseAccess)
+}
+
+// issueTokens starts a session for the requesting device and creates an access
+// token and a refresh token that starts a new family.
+func (app *application) issueTokens(c *gin.Context, userId int) (*tokenResponse, error) {
+	session, err := app.models.Sessions.Create(userId, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := app.newAccessToken(userId, session.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := app.models.RefreshTokens.Create(userId, session.Id, refreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tokenResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(accessTokenTTL.Seconds()),
+	}, nil
+}
+
+// refreshToken exchanges a refresh token for a new token pair
+//
+// @Summary Refresh access token
+// @Description Rotate a refresh token and issue a new access token. Reusing an already rotated refresh token revokes the whole token family.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body refreshRequest true "Refresh token"
+// @Success 200 {object} tokenResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/refresh [post]
+func (app *application) refreshToken(c *gin.Context) {
+	var req refreshRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userId, sessionId, refreshToken, err := app.models.RefreshTokens.Rotate(req.RefreshToken, refreshTokenTTL)
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidToken) || errors.Is(err, database.ErrTokenReuse) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	user, err := app.models.Users.Get(userId)
+	if err != nil || user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+
+	if rejectDisabled(c, user) {
+		return
+	}
+
+	accessToken, err := app.newAccessToken(userId, sessionId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong, not able to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokenResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(accessTokenTTL.Seconds()),
+	})
+}
+
+// logout ends the current session
+//
+// @Summary Logout
+// @Description Revoke the current session together with its access and refresh tokens
+// @Tags authentication
+// @Success 204 "Logged out"
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/logout [post]
+func (app *application) logout(c *gin.Context) {
+	user := app.GetUserFromContext(c)
+	claims := app.GetClaimsFromContext(c)
+
+	if _, err := app.models.Sessions.Revoke(claims.SessionId, user.Id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to logout"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// jwks publishes the public signing keys
+//
+// @Summary JSON Web Key Set
+// @Description Public keys for verifying access tokens. Empty when tokens are signed with HMAC.
+// @Tags authentication
+// @Produce json
+// @Success 200 {object} signing.JWKSet
+// @Router /.well-known/jwks.json [get]
+func (app *application) jwks(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, app.keys.JWKS())
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"event-api-app/internal/database"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// verifyTokenTTL is how long an email verification link stays valid.
+const verifyTokenTTL = 24 * time.Hour
+
+type verifyEmailRequest struct {
+	Token string `json:"token" form:"token" binding:"required"`
+}
+
+type resendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// verifyEmail marks a user as verified using the token issued at registration
+//
+// @Summary Verify email address
+// @Description Consume an email verification token and mark the user as verified
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param token query string false "Verification token (GET)"
+// @Param request body verifyEmailRequest false "Verification token (POST)"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/verify [get]
+// @Router /auth/verify [post]
+func (app *application) verifyEmail(c *gin.Context) {
+	var req verifyEmailRequest
+
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := app.models.Tokens.Consume(database.TokenPurposeVerify, req.Token)
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	user, err := app.models.Users.Get(token.UserId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	// token 只驗證寄送當時的信箱；之後變更過 email 則失效
+	if user == nil || user.Email != token.Payload {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification token"})
+		return
+	}
+
+	if err := app.models.Users.MarkVerified(user.Id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+}
+
+// resendVerification issues a fresh verification token for an unverified user
+//
+// @Summary Resend verification email
+// @Description Issue a new email verification token for an unverified account, invalidating the previous one. The response does not reveal whether the email exists.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body resendVerificationRequest true "Account email"
+// @Success 202 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/verify/resend [post]
+func (app *application) resendVerification(c *gin.Context) {
+	var req resendVerificationRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := app.models.Users.GetByEmail(req.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	// 不論帳號是否存在都回傳相同訊息，避免洩漏註冊狀態
+	if user != nil && !user.Verified {
+		if err := app.sendVerificationEmail(user); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the account exists and is not yet verified, a new verification email has been sent"})
+}
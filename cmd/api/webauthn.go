@@ -0,0 +1,574 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"event-api-app/internal/database"
+	"event-api-app/internal/env"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+const webAuthnCeremonyTTL = 5 * time.Minute
+
+// Ceremony purposes stored with the server-side session data.
+const (
+	ceremonyRegister = "register"
+	ceremonyLogin    = "login"
+)
+
+type webAuthnBeginResponse struct {
+	CeremonyId string `json:"ceremony_id"`
+	Options    any    `json:"options"`
+}
+
+type finishPasskeyRegistrationRequest struct {
+	CeremonyId string          `json:"ceremony_id" binding:"required"`
+	Name       string          `json:"name" binding:"required,min=1,max=64"`
+	Credential json.RawMessage `json:"credential" binding:"required"`
+}
+
+type beginPasskeyLoginRequest struct {
+	Email string `json:"email" binding:"omitempty,email"`
+}
+
+type finishPasskeyLoginRequest struct {
+	CeremonyId string          `json:"ceremony_id" binding:"required"`
+	Credential json.RawMessage `json:"credential" binding:"required"`
+}
+
+type renamePasskeyRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=64"`
+}
+
+// newWebAuthn 設定 relying party：WEBAUTHN_RP_ID 預設為 APP_BASE_URL 的主機名稱，
+// WEBAUTHN_RP_ORIGINS（以逗號分隔）預設為 APP_BASE_URL
+func newWebAuthn(baseURL string) (*webauthn.WebAuthn, error) {
+	rpID := env.GetEnvString("WEBAUTHN_RP_ID", "")
+	if rpID == "" {
+		u, err := url.Parse(baseURL)
+		if err != nil {
+			return nil, err
+		}
+		rpID = u.Hostname()
+	}
+
+	return webauthn.New(&webauthn.Config{
+		RPID:          rpID,
+		RPDisplayName: env.GetEnvString("WEBAUTHN_RP_NAME", "Event API"),
+		RPOrigins:     strings.Split(env.GetEnvString("WEBAUTHN_RP_ORIGINS", baseURL), ","),
+	})
+}
+
+// webAuthnUser adapts a user and their stored credentials to webauthn.User.
+type webAuthnUser struct {
+	user        *database.User
+	handle      []byte
+	credentials []*database.WebAuthnCredential
+}
+
+func (u *webAuthnUser) WebAuthnID() []byte          { return u.handle }
+func (u *webAuthnUser) WebAuthnName() string        { return u.user.Email }
+func (u *webAuthnUser) WebAuthnDisplayName() string { return u.user.Name }
+
+func (u *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, 0, len(u.credentials))
+	for _, cred := range u.credentials {
+		transports := make([]protocol.AuthenticatorTransport, 0, len(cred.Transports))
+		for _, transport := range cred.Transports {
+			transports = append(transports, protocol.AuthenticatorTransport(transport))
+		}
+
+		credentials = append(credentials, webauthn.Credential{
+			ID:              cred.CredentialId,
+			PublicKey:       cred.PublicKey,
+			AttestationType: cred.AttestationType,
+			Transport:       transports,
+			Flags: webauthn.CredentialFlags{
+				BackupEligible: cred.BackupEligible,
+				BackupState:    cred.BackupState,
+			},
+			Authenticator: webauthn.Authenticator{
+				AAGUID:       cred.AAGUID,
+				SignCount:    cred.SignCount,
+				CloneWarning: cred.CloneWarning,
+			},
+		})
+	}
+	return credentials
+}
+
+// credential returns the stored credential with the given credential ID.
+func (u *webAuthnUser) credential(id []byte) *database.WebAuthnCredential {
+	for _, cred := range u.credentials {
+		if bytes.Equal(cred.CredentialId, id) {
+			return cred
+		}
+	}
+	return nil
+}
+
+// exclusions lists the user's passkeys so the authenticator does not register
+// one of them again.
+func (u *webAuthnUser) exclusions() []protocol.CredentialDescriptor {
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(u.credentials))
+	for _, cred := range u.WebAuthnCredentials() {
+		exclusions = append(exclusions, cred.Descriptor())
+	}
+	return exclusions
+}
+
+// newStoredPasskey converts a newly registered credential into the row stored
+// for the user.
+func newStoredPasskey(userId int, name string, credential *webauthn.Credential) *database.WebAuthnCredential {
+	transports := make([]string, 0, len(credential.Transport))
+	for _, transport := range credential.Transport {
+		transports = append(transports, string(transport))
+	}
+
+	return &database.WebAuthnCredential{
+		UserId:          userId,
+		Name:            name,
+		CredentialId:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		Transports:      transports,
+		AAGUID:          credential.Authenticator.AAGUID,
+		SignCount:       credential.Authenticator.SignCount,
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+	}
+}
+
+// loadWebAuthnUser loads the user handle and credentials of user.
+func (app *application) loadWebAuthnUser(user *database.User) (*webAuthnUser, error) {
+	handle, err := app.models.WebAuthn.GetOrCreateHandle(user.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := app.models.WebAuthn.GetAllForUser(user.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webAuthnUser{user: user, handle: handle, credentials: credentials}, nil
+}
+
+// webAuthnError describes a failed ceremony without exposing debug information.
+func webAuthnError(err error) string {
+	var protocolErr *protocol.Error
+	if errors.As(err, &protocolErr) && protocolErr.Details != "" {
+		return "Passkey verification failed: " + protocolErr.Details
+	}
+	return "Passkey verification failed"
+}
+
+// beginPasskeyRegistration starts registering a passkey for the current user
+//
+// @Summary Begin passkey registration
+// @Description Returns a ceremony ID and the options to pass to navigator.credentials.create()
+// @Tags webauthn
+// @Produce json
+// @Success 200 {object} webAuthnBeginResponse
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/webauthn/register/begin [post]
+func (app *application) beginPasskeyRegistration(c *gin.Context) {
+	u, err := app.loadWebAuthnUser(app.GetUserFromContext(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	// 已註冊的 passkey 不可重複註冊
+	creation, session, err := app.webauthn.BeginRegistration(u,
+		webauthn.WithExclusions(u.exclusions()),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementPreferred),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	app.startCeremony(c, ceremonyRegister, u.user.Id, session, creation)
+}
+
+// finishPasskeyRegistration stores the passkey created by the authenticator
+//
+// @Summary Finish passkey registration
+// @Description Verify the authenticator's attestation and save the passkey under the given name
+// @Tags webauthn
+// @Accept json
+// @Produce json
+// @Param request body finishPasskeyRegistrationRequest true "Ceremony ID, passkey name and the credential returned by the browser"
+// @Success 201 {object} database.WebAuthnCredential
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/webauthn/register/finish [post]
+func (app *application) finishPasskeyRegistration(c *gin.Context) {
+	var req finishPasskeyRegistrationRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := app.GetUserFromContext(c)
+
+	session, userId, ok := app.finishCeremony(c, req.CeremonyId, ceremonyRegister)
+	if !ok {
+		return
+	}
+
+	if userId != user.Id {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired ceremony"})
+		return
+	}
+
+	u, err := app.loadWebAuthnUser(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(req.Credential)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": webAuthnError(err)})
+		return
+	}
+
+	credential, err := app.webauthn.CreateCredential(u, *session, parsed)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": webAuthnError(err)})
+		return
+	}
+
+	if u.credential(credential.ID) != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Passkey is already registered"})
+		return
+	}
+
+	cred := newStoredPasskey(user.Id, req.Name, credential)
+
+	if err := app.models.WebAuthn.Insert(cred); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save passkey"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, cred)
+}
+
+// beginPasskeyLogin starts a passkey login
+//
+// @Summary Begin passkey login
+// @Description Returns a ceremony ID and the options to pass to navigator.credentials.get(). Without an email, or for an account without passkeys, any discoverable passkey can be used.
+// @Tags webauthn
+// @Accept json
+// @Produce json
+// @Param request body beginPasskeyLoginRequest false "Account email"
+// @Success 200 {object} webAuthnBeginResponse
+// @Failure 400 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/webauthn/login/begin [post]
+func (app *application) beginPasskeyLogin(c *gin.Context) {
+	var req beginPasskeyLoginRequest
+
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var u *webAuthnUser
+	if req.Email != "" {
+		user, err := app.models.Users.GetByEmail(req.Email)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+			return
+		}
+
+		if user != nil {
+			if u, err = app.loadWebAuthnUser(user); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+				return
+			}
+		}
+	}
+
+	// 未知的帳號或沒有 passkey 時改用 discoverable login，避免洩漏帳號是否存在
+	var assertion *protocol.CredentialAssertion
+	var session *webauthn.SessionData
+	var err error
+	userId := 0
+
+	if u != nil && len(u.credentials) > 0 {
+		assertion, session, err = app.webauthn.BeginLogin(u)
+		userId = u.user.Id
+	} else {
+		assertion, session, err = app.webauthn.BeginDiscoverableLogin()
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	app.startCeremony(c, ceremonyLogin, userId, session, assertion)
+}
+
+// finishPasskeyLogin signs the user in with a passkey
+//
+// @Summary Finish passkey login
+// @Description Verify the authenticator's assertion and its sign counter. Responds like /auth/login; a user-verified passkey also satisfies TOTP.
+// @Tags webauthn
+// @Accept json
+// @Produce json
+// @Param request body finishPasskeyLoginRequest true "Ceremony ID and the credential returned by the browser"
+// @Success 200 {object} loginResponse
+// @Success 202 {object} mfaChallengeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/webauthn/login/finish [post]
+func (app *application) finishPasskeyLogin(c *gin.Context) {
+	var req finishPasskeyLoginRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	session, userId, ok := app.finishCeremony(c, req.CeremonyId, ceremonyLogin)
+	if !ok {
+		return
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(req.Credential)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": webAuthnError(err)})
+		return
+	}
+
+	var u *webAuthnUser
+	var credential *webauthn.Credential
+
+	if userId != 0 {
+		user, err := app.models.Users.Get(userId)
+		if err != nil || user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Passkey verification failed"})
+			return
+		}
+
+		if u, err = app.loadWebAuthnUser(user); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+			return
+		}
+
+		credential, err = app.webauthn.ValidateLogin(u, *session, parsed)
+	} else {
+		credential, err = app.webauthn.ValidateDiscoverableLogin(func(rawID, userHandle []byte) (webauthn.User, error) {
+			id, err := app.models.WebAuthn.GetUserIdByHandle(userHandle)
+			if err != nil {
+				return nil, err
+			}
+
+			user, err := app.models.Users.Get(id)
+			if err != nil {
+				return nil, err
+			}
+			if user == nil {
+				return nil, protocol.ErrBadRequest.WithDetails("Unknown user handle")
+			}
+
+			u, err = app.loadWebAuthnUser(user)
+			return u, err
+		}, *session, parsed)
+	}
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": webAuthnError(err)})
+		return
+	}
+
+	stored := u.credential(credential.ID)
+	if stored == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Passkey verification failed"})
+		return
+	}
+
+	// 簽章計數器沒有遞增代表金鑰可能遭複製，該 passkey 之後一律拒絕
+	if err := app.models.WebAuthn.RecordLogin(stored.Id, credential.Authenticator.SignCount, credential.Authenticator.CloneWarning, credential.Flags.BackupState); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	if credential.Authenticator.CloneWarning {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Passkey sign counter check failed; remove this passkey and register it again"})
+		return
+	}
+
+	// 經過用戶驗證（PIN 或生物辨識）的 passkey 已是多因素，不再要求 TOTP
+	if credential.Flags.UserVerified {
+		if rejectDisabled(c, u.user) {
+			return
+		}
+		app.issueLoginResponse(c, u.user)
+		return
+	}
+
+	app.completeLogin(c, u.user)
+}
+
+// startCeremony stores the ceremony session and responds with its ID and the browser options.
+func (app *application) startCeremony(c *gin.Context, purpose string, userId int, session *webauthn.SessionData, options any) {
+	data, err := json.Marshal(session)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	ceremonyId, err := app.models.WebAuthn.StartCeremony(purpose, userId, data, webAuthnCeremonyTTL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+
+	c.JSON(http.StatusOK, webAuthnBeginResponse{CeremonyId: ceremonyId, Options: options})
+}
+
+// finishCeremony consumes a ceremony, writing an error response and returning
+// false when it is invalid.
+func (app *application) finishCeremony(c *gin.Context, ceremonyId, purpose string) (*webauthn.SessionData, int, bool) {
+	userId, data, err := app.models.WebAuthn.FinishCeremony(ceremonyId, purpose)
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired ceremony"})
+			return nil, 0, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return nil, 0, false
+	}
+
+	var session webauthn.SessionData
+	if err := json.Unmarshal(data, &session); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return nil, 0, false
+	}
+
+	return &session, userId, true
+}
+
+// getPasskeys lists the current user's passkeys
+//
+// @Summary List passkeys
+// @Tags webauthn
+// @Produce json
+// @Success 200 {array} database.WebAuthnCredential
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/webauthn/credentials [get]
+func (app *application) getPasskeys(c *gin.Context) {
+	user := app.GetUserFromContext(c)
+
+	credentials, err := app.models.WebAuthn.GetAllForUser(user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve passkeys"})
+		return
+	}
+
+	c.JSON(http.StatusOK, credentials)
+}
+
+// renamePasskey renames one of the current user's passkeys
+//
+// @Summary Rename passkey
+// @Tags webauthn
+// @Accept json
+// @Param id path int true "Passkey ID"
+// @Param request body renamePasskeyRequest true "New name"
+// @Success 204 "Passkey renamed"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/webauthn/credentials/{id} [put]
+func (app *application) renamePasskey(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid passkey ID"})
+		return
+	}
+
+	var req renamePasskeyRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := app.GetUserFromContext(c)
+
+	found, err := app.models.WebAuthn.Rename(id, user.Id, req.Name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rename passkey"})
+		return
+	}
+
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Passkey not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// deletePasskey removes one of the current user's passkeys
+//
+// @Summary Remove passkey
+// @Tags webauthn
+// @Param id path int true "Passkey ID"
+// @Success 204 "Passkey removed"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/webauthn/credentials/{id} [delete]
+func (app *application) deletePasskey(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid passkey ID"})
+		return
+	}
+
+	user := app.GetUserFromContext(c)
+
+	found, err := app.models.WebAuthn.Delete(id, user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove passkey"})
+		return
+	}
+
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Passkey not found"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
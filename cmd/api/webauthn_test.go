@@ -0,0 +1,512 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"event-api-app/internal/database"
+	"testing"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+const testOrigin = "http://localhost:8080"
+
+// Authenticator data flags (WebAuthn §6.1).
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagAttested     = 0x40
+)
+
+// softAuthenticator is a software passkey with a P-256 key and "none"
+// attestation. It answers the options returned by BeginRegistration and
+// BeginLogin the way a browser and a platform authenticator would.
+type softAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialId []byte
+	userHandle   []byte
+	signCount    uint32
+	origin       string
+	userVerified bool
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	credentialId := make([]byte, 16)
+	if _, err := rand.Read(credentialId); err != nil {
+		t.Fatal(err)
+	}
+
+	return &softAuthenticator{key: key, credentialId: credentialId, origin: testOrigin, userVerified: true}
+}
+
+func (a *softAuthenticator) authenticatorData(rpID string, attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+
+	flags := byte(flagUserPresent)
+	if a.userVerified {
+		flags |= flagUserVerified
+	}
+	if attested {
+		flags |= flagAttested
+	}
+
+	data := append(rpIDHash[:], flags)
+	return binary.BigEndian.AppendUint32(data, a.signCount)
+}
+
+func (a *softAuthenticator) clientData(t *testing.T, ceremony, challenge string) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]string{
+		"type":      ceremony,
+		"challenge": challenge,
+		"origin":    a.origin,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// register returns the credential JSON the browser posts to
+// /auth/webauthn/register/finish.
+func (a *softAuthenticator) register(t *testing.T, creation *protocol.CredentialCreation) []byte {
+	t.Helper()
+
+	a.userHandle = creation.Response.User.ID.(protocol.URLEncodedBase64)
+
+	publicKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  int64(webauthncose.P256),
+		XCoord: a.key.X.FillBytes(make([]byte, 32)),
+		YCoord: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authData := a.authenticatorData(creation.Response.RelyingParty.ID, true)
+	authData = append(authData, make([]byte, 16)...) // AAGUID
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(a.credentialId)))
+	authData = append(authData, a.credentialId...)
+	authData = append(authData, publicKey...)
+
+	attestationObject, err := webauthncbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": authData,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return a.credentialJSON(t, map[string]any{
+		"clientDataJSON":    encode(a.clientData(t, "webauthn.create", creation.Response.Challenge.String())),
+		"attestationObject": encode(attestationObject),
+		"transports":        []string{"internal"},
+	})
+}
+
+// assert returns the credential JSON the browser posts to
+// /auth/webauthn/login/finish, bumping the sign counter first.
+func (a *softAuthenticator) assert(t *testing.T, assertion *protocol.CredentialAssertion) []byte {
+	t.Helper()
+
+	a.signCount++
+
+	authData := a.authenticatorData(assertion.Response.RelyingPartyID, false)
+	clientData := a.clientData(t, "webauthn.get", assertion.Response.Challenge.String())
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(bytes.Clone(authData), clientDataHash[:]...))
+
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return a.credentialJSON(t, map[string]any{
+		"clientDataJSON":    encode(clientData),
+		"authenticatorData": encode(authData),
+		"signature":         encode(signature),
+		"userHandle":        encode(a.userHandle),
+	})
+}
+
+func (a *softAuthenticator) credentialJSON(t *testing.T, response map[string]any) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]any{
+		"id":       encode(a.credentialId),
+		"rawId":    encode(a.credentialId),
+		"type":     "public-key",
+		"response": response,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// roundTripSession stores and loads the session the way startCeremony and
+// finishCeremony do.
+func roundTripSession(t *testing.T, session *webauthn.SessionData) webauthn.SessionData {
+	t.Helper()
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var loaded webauthn.SessionData
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	return loaded
+}
+
+func newTestWebAuthn(t *testing.T) *webauthn.WebAuthn {
+	t.Helper()
+
+	w, err := newWebAuthn(testOrigin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func newTestWebAuthnUser() *webAuthnUser {
+	return &webAuthnUser{
+		user:   &database.User{Id: 1, Email: "alice@example.com", Name: "Alice"},
+		handle: []byte("handle-of-user-1"),
+	}
+}
+
+// registerPasskey runs the registration ceremony and stores the passkey on u.
+func registerPasskey(t *testing.T, w *webauthn.WebAuthn, u *webAuthnUser, a *softAuthenticator) *database.WebAuthnCredential {
+	t.Helper()
+
+	creation, session, err := w.BeginRegistration(u, webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementPreferred))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(a.register(t, creation))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	credential, err := w.CreateCredential(u, roundTripSession(t, session), parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored := newStoredPasskey(u.user.Id, "Laptop", credential)
+	stored.Id = len(u.credentials) + 1
+	u.credentials = append(u.credentials, stored)
+
+	return stored
+}
+
+func TestPasskeyRegistration(t *testing.T) {
+	w := newTestWebAuthn(t)
+	u := newTestWebAuthnUser()
+	a := newSoftAuthenticator(t)
+
+	stored := registerPasskey(t, w, u, a)
+
+	if !bytes.Equal(stored.CredentialId, a.credentialId) {
+		t.Errorf("got credential ID %x, want %x", stored.CredentialId, a.credentialId)
+	}
+	if stored.UserId != u.user.Id || stored.Name != "Laptop" || stored.AttestationType != "none" {
+		t.Errorf("got passkey %+v", stored)
+	}
+	if len(stored.Transports) != 1 || stored.Transports[0] != "internal" {
+		t.Errorf("got transports %v", stored.Transports)
+	}
+
+	// 之後的註冊選項要排除已註冊的 passkey
+	creation, _, err := w.BeginRegistration(u, webauthn.WithExclusions(u.exclusions()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if excluded := creation.Response.CredentialExcludeList; len(excluded) != 1 || !bytes.Equal(excluded[0].CredentialID, a.credentialId) {
+		t.Errorf("got exclude list %+v", excluded)
+	}
+}
+
+func TestPasskeyRegistrationRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*softAuthenticator, *protocol.CredentialCreation)
+	}{
+		{
+			name:   "wrong origin",
+			modify: func(a *softAuthenticator, _ *protocol.CredentialCreation) { a.origin = "https://attacker.example.com" },
+		},
+		{
+			name: "wrong challenge",
+			modify: func(_ *softAuthenticator, creation *protocol.CredentialCreation) {
+				creation.Response.Challenge = protocol.URLEncodedBase64("another challenge")
+			},
+		},
+		{
+			name: "wrong relying party",
+			modify: func(_ *softAuthenticator, creation *protocol.CredentialCreation) {
+				creation.Response.RelyingParty.ID = "attacker.example.com"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWebAuthn(t)
+			u := newTestWebAuthnUser()
+			a := newSoftAuthenticator(t)
+
+			creation, session, err := w.BeginRegistration(u)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tt.modify(a, creation)
+
+			parsed, err := protocol.ParseCredentialCreationResponseBytes(a.register(t, creation))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := w.CreateCredential(u, roundTripSession(t, session), parsed); err == nil {
+				t.Fatal("expected registration to fail")
+			}
+		})
+	}
+}
+
+// recordLogin applies the result of a login to the stored passkey like
+// WebAuthnModel.RecordLogin.
+func recordLogin(stored *database.WebAuthnCredential, credential *webauthn.Credential) {
+	stored.SignCount = credential.Authenticator.SignCount
+	stored.CloneWarning = stored.CloneWarning || credential.Authenticator.CloneWarning
+}
+
+func TestPasskeyLogin(t *testing.T) {
+	w := newTestWebAuthn(t)
+	u := newTestWebAuthnUser()
+	a := newSoftAuthenticator(t)
+	stored := registerPasskey(t, w, u, a)
+
+	for i := 1; i <= 2; i++ {
+		assertion, session, err := w.BeginLogin(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parsed, err := protocol.ParseCredentialRequestResponseBytes(a.assert(t, assertion))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		credential, err := w.ValidateLogin(u, roundTripSession(t, session), parsed)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if u.credential(credential.ID) != stored {
+			t.Fatalf("login %d: credential %x is not the registered passkey", i, credential.ID)
+		}
+		if credential.Authenticator.CloneWarning {
+			t.Fatalf("login %d: unexpected clone warning", i)
+		}
+		if credential.Authenticator.SignCount != uint32(i) {
+			t.Errorf("login %d: got sign count %d", i, credential.Authenticator.SignCount)
+		}
+		if !credential.Flags.UserVerified {
+			t.Errorf("login %d: expected user verification", i)
+		}
+
+		recordLogin(stored, credential)
+	}
+}
+
+func TestPasskeyLoginUserPresenceOnly(t *testing.T) {
+	w := newTestWebAuthn(t)
+	u := newTestWebAuthnUser()
+	a := newSoftAuthenticator(t)
+	registerPasskey(t, w, u, a)
+
+	// 沒有 PIN 或生物辨識的登入仍需 TOTP，finishPasskeyLogin 依 UserVerified 判斷
+	a.userVerified = false
+
+	assertion, session, err := w.BeginLogin(u, webauthn.WithUserVerification(protocol.VerificationDiscouraged))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(a.assert(t, assertion))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	credential, err := w.ValidateLogin(u, roundTripSession(t, session), parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if credential.Flags.UserVerified {
+		t.Error("expected no user verification")
+	}
+}
+
+func TestPasskeyLoginDetectsClonedAuthenticator(t *testing.T) {
+	w := newTestWebAuthn(t)
+	u := newTestWebAuthnUser()
+	a := newSoftAuthenticator(t)
+	stored := registerPasskey(t, w, u, a)
+
+	// 複製出來的金鑰與原本的共用私鑰，但計數器各自遞增
+	clone := *a
+
+	login := func(a *softAuthenticator) *webauthn.Credential {
+		assertion, session, err := w.BeginLogin(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parsed, err := protocol.ParseCredentialRequestResponseBytes(a.assert(t, assertion))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		credential, err := w.ValidateLogin(u, roundTripSession(t, session), parsed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return credential
+	}
+
+	credential := login(a)
+	if credential.Authenticator.CloneWarning {
+		t.Fatal("unexpected clone warning on the first login")
+	}
+	recordLogin(stored, credential)
+
+	if credential := login(&clone); !credential.Authenticator.CloneWarning {
+		t.Fatalf("expected a clone warning for sign count %d after %d", credential.Authenticator.SignCount, stored.SignCount)
+	}
+}
+
+func TestPasskeyDiscoverableLogin(t *testing.T) {
+	w := newTestWebAuthn(t)
+	u := newTestWebAuthnUser()
+	a := newSoftAuthenticator(t)
+	registerPasskey(t, w, u, a)
+
+	assertion, session, err := w.BeginDiscoverableLogin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assertion.Response.AllowedCredentials) != 0 {
+		t.Errorf("got allowed credentials %+v, want none", assertion.Response.AllowedCredentials)
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(a.assert(t, assertion))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	credential, err := w.ValidateDiscoverableLogin(func(rawID, userHandle []byte) (webauthn.User, error) {
+		if !bytes.Equal(userHandle, u.handle) {
+			return nil, protocol.ErrBadRequest.WithDetails("Unknown user handle")
+		}
+		return u, nil
+	}, roundTripSession(t, session), parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(credential.ID, a.credentialId) {
+		t.Errorf("got credential ID %x, want %x", credential.ID, a.credentialId)
+	}
+}
+
+func TestPasskeyLoginRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*softAuthenticator, *protocol.CredentialAssertion)
+	}{
+		{
+			name:   "wrong origin",
+			modify: func(a *softAuthenticator, _ *protocol.CredentialAssertion) { a.origin = "https://attacker.example.com" },
+		},
+		{
+			name: "wrong challenge",
+			modify: func(_ *softAuthenticator, assertion *protocol.CredentialAssertion) {
+				assertion.Response.Challenge = protocol.URLEncodedBase64("another challenge")
+			},
+		},
+		{
+			name: "wrong relying party",
+			modify: func(_ *softAuthenticator, assertion *protocol.CredentialAssertion) {
+				assertion.Response.RelyingPartyID = "attacker.example.com"
+			},
+		},
+		{
+			name: "unregistered key",
+			modify: func(a *softAuthenticator, _ *protocol.CredentialAssertion) {
+				other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				if err != nil {
+					panic(err)
+				}
+				a.key = other
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWebAuthn(t)
+			u := newTestWebAuthnUser()
+			a := newSoftAuthenticator(t)
+			registerPasskey(t, w, u, a)
+
+			assertion, session, err := w.BeginLogin(u)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tt.modify(a, assertion)
+
+			parsed, err := protocol.ParseCredentialRequestResponseBytes(a.assert(t, assertion))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := w.ValidateLogin(u, roundTripSession(t, session), parsed); err == nil {
+				t.Fatal("expected login to fail")
+			}
+		})
+	}
+}
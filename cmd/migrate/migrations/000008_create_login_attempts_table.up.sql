@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS login_attempts (
+  key TEXT PRIMARY KEY,
+  failed_count INTEGER NOT NULL DEFAULT 0,
+  last_failed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  blocked_until TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS account_lockouts (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER,
+  key TEXT NOT NULL,
+  ip TEXT NOT NULL,
+  failed_count INTEGER NOT NULL,
+  locked_until TIMESTAMP WITH TIME ZONE NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  unlocked_at TIMESTAMP WITH TIME ZONE,
+  unlocked_by INTEGER,
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL,
+  FOREIGN KEY (unlocked_by) REFERENCES users (id) ON DELETE SET NULL
+);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS user_mfa (
+  user_id INTEGER PRIMARY KEY,
+  totp_secret TEXT NOT NULL,
+  enabled_at TIMESTAMP WITH TIME ZONE,
+  last_used_step BIGINT NOT NULL DEFAULT 0,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL,
+  code_hash TEXT NOT NULL,
+  used_at TIMESTAMP WITH TIME ZONE,
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS mfa_recovery_codes_user_id_idx ON mfa_recovery_codes (user_id);
@@ -0,0 +1,4 @@
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_fkey;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,67 @@
+CREATE TABLE IF NOT EXISTS roles (
+  name TEXT PRIMARY KEY,
+  description TEXT NOT NULL DEFAULT '',
+  is_default BOOLEAN NOT NULL DEFAULT false
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS roles_single_default_idx ON roles (is_default) WHERE is_default;
+
+CREATE TABLE IF NOT EXISTS permissions (
+  name TEXT PRIMARY KEY,
+  description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+  role TEXT NOT NULL,
+  permission TEXT NOT NULL,
+  PRIMARY KEY (role, permission),
+  FOREIGN KEY (role) REFERENCES roles (name) ON UPDATE CASCADE ON DELETE CASCADE,
+  FOREIGN KEY (permission) REFERENCES permissions (name) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+INSERT INTO roles (name, description, is_default) VALUES
+  ('user', 'Regular account', true),
+  ('moderator', 'Can manage any event and its attendees', false),
+  ('admin', 'Full access', false)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO permissions (name, description) VALUES
+  ('events.create', 'Create events'),
+  ('events.update.own', 'Update events you own'),
+  ('events.update.any', 'Update any event'),
+  ('events.delete.own', 'Delete events you own'),
+  ('events.delete.any', 'Delete any event'),
+  ('attendees.remove.own', 'Remove yourself from an event'),
+  ('attendees.remove.any', 'Remove any attendee from an event'),
+  ('users.manage', 'Manage user accounts')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+  ('user', 'events.create'),
+  ('user', 'events.update.own'),
+  ('user', 'events.delete.own'),
+  ('user', 'attendees.remove.own'),
+  ('moderator', 'events.create'),
+  ('moderator', 'events.update.own'),
+  ('moderator', 'events.update.any'),
+  ('moderator', 'events.delete.own'),
+  ('moderator', 'events.delete.any'),
+  ('moderator', 'attendees.remove.own'),
+  ('moderator', 'attendees.remove.any'),
+  ('admin', 'events.create'),
+  ('admin', 'events.update.own'),
+  ('admin', 'events.update.any'),
+  ('admin', 'events.delete.own'),
+  ('admin', 'events.delete.any'),
+  ('admin', 'attendees.remove.own'),
+  ('admin', 'attendees.remove.any'),
+  ('admin', 'users.manage')
+ON CONFLICT DO NOTHING;
+
+-- 既有用戶若使用了未知的角色，先補進 roles 再加上外鍵
+INSERT INTO roles (name)
+SELECT DISTINCT role FROM users
+ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE users
+ADD CONSTRAINT users_role_fkey FOREIGN KEY (role) REFERENCES roles (name) ON UPDATE CASCADE;
@@ -0,0 +1,3 @@
+ALTER TABLE users
+ADD COLUMN disabled_at timestamp with time zone,
+ADD COLUMN created_at timestamp with time zone NOT NULL DEFAULT NOW();
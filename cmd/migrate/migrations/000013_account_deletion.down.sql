@@ -0,0 +1,9 @@
+DELETE FROM events WHERE owner_id IS NULL;
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_owner_id_fkey;
+ALTER TABLE events
+ADD CONSTRAINT events_owner_id_fkey FOREIGN KEY (owner_id) REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE events ALTER COLUMN owner_id SET NOT NULL;
+
+ALTER TABLE users
+DROP COLUMN deletion_scheduled_at,
+DROP COLUMN deletion_mode;
@@ -0,0 +1,9 @@
+ALTER TABLE users
+ADD COLUMN deletion_scheduled_at timestamp with time zone,
+ADD COLUMN deletion_mode text;
+
+-- 刪除用戶時保留其活動與參加者名單，改由刪除流程決定是否一併刪除活動
+ALTER TABLE events ALTER COLUMN owner_id DROP NOT NULL;
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_owner_id_fkey;
+ALTER TABLE events
+ADD CONSTRAINT events_owner_id_fkey FOREIGN KEY (owner_id) REFERENCES users (id) ON DELETE SET NULL;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS sessions (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL,
+  user_agent TEXT NOT NULL DEFAULT '',
+  ip TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  revoked_at TIMESTAMP WITH TIME ZONE,
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions (user_id);
+
+-- 既有的 refresh token 沒有對應的 session，一律作廢
+DELETE FROM refresh_tokens;
+
+ALTER TABLE refresh_tokens
+  ADD COLUMN session_id INTEGER NOT NULL REFERENCES sessions (id) ON DELETE CASCADE;
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS webauthn_users (
+  user_id INTEGER PRIMARY KEY,
+  handle BYTEA NOT NULL UNIQUE,
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS webauthn_credentials (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL,
+  name TEXT NOT NULL,
+  credential_id BYTEA NOT NULL UNIQUE,
+  public_key BYTEA NOT NULL,
+  attestation_type TEXT NOT NULL DEFAULT '',
+  transports TEXT[] NOT NULL DEFAULT '{}',
+  aaguid BYTEA,
+  sign_count BIGINT NOT NULL DEFAULT 0,
+  clone_warning BOOLEAN NOT NULL DEFAULT false,
+  backup_eligible BOOLEAN NOT NULL DEFAULT false,
+  backup_state BOOLEAN NOT NULL DEFAULT false,
+  last_used_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS webauthn_credentials_user_id_idx ON webauthn_credentials (user_id);
+
+CREATE TABLE IF NOT EXISTS webauthn_ceremonies (
+  token_hash TEXT PRIMARY KEY,
+  purpose TEXT NOT NULL,
+  user_id INTEGER,
+  session_data BYTEA NOT NULL,
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
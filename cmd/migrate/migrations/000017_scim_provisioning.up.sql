@@ -0,0 +1,5 @@
+-- SCIM 用戶端（例如 HR 系統）指定的外部識別碼
+ALTER TABLE users
+ADD COLUMN external_id text;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_external_id_idx ON users (external_id);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS events_organization_id_idx;
+
+ALTER TABLE events
+DROP COLUMN IF EXISTS organization_id,
+DROP COLUMN IF EXISTS visibility;
+
+DROP TABLE IF EXISTS organization_members;
+DROP TABLE IF EXISTS organizations;
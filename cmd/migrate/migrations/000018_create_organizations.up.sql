@@ -0,0 +1,43 @@
+CREATE TABLE IF NOT EXISTS organizations (
+  id SERIAL PRIMARY KEY,
+  slug TEXT NOT NULL UNIQUE,
+  name TEXT NOT NULL,
+  is_default BOOLEAN NOT NULL DEFAULT false,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS organizations_single_default_idx ON organizations (is_default) WHERE is_default;
+
+CREATE TABLE IF NOT EXISTS organization_members (
+  organization_id INTEGER NOT NULL,
+  user_id INTEGER NOT NULL,
+  role TEXT NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'admin', 'member')),
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (organization_id, user_id),
+  FOREIGN KEY (organization_id) REFERENCES organizations (id) ON DELETE CASCADE,
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS organization_members_user_id_idx ON organization_members (user_id);
+
+-- 既有的活動與用戶都歸入預設組織，未指定組織的請求也使用它
+INSERT INTO organizations (slug, name, is_default) VALUES ('default', 'Default', true)
+ON CONFLICT (slug) DO NOTHING;
+
+INSERT INTO organization_members (organization_id, user_id, role)
+SELECT o.id, u.id, 'member'
+FROM organizations o CROSS JOIN users u
+WHERE o.is_default
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE events
+ADD COLUMN organization_id INTEGER,
+ADD COLUMN visibility TEXT NOT NULL DEFAULT 'public' CHECK (visibility IN ('public', 'private'));
+
+UPDATE events SET organization_id = (SELECT id FROM organizations WHERE is_default);
+
+ALTER TABLE events ALTER COLUMN organization_id SET NOT NULL;
+ALTER TABLE events
+ADD CONSTRAINT events_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations (id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS events_organization_id_idx ON events (organization_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS event_hosts_user_id_idx;
+DROP TABLE IF EXISTS event_hosts;
@@ -0,0 +1,38 @@
+-- 驗證 token 只存雜湊，無法還原明文；未驗證的用戶需重新寄送驗證信
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS verify_token text,
+ADD COLUMN IF NOT EXISTS verify_token_expires timestamp with time zone;
+
+CREATE TABLE IF NOT EXISTS password_resets (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL,
+  token_hash TEXT NOT NULL UNIQUE,
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  used_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS email_changes (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL UNIQUE,
+  new_email TEXT NOT NULL,
+  token_hash TEXT NOT NULL UNIQUE,
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+INSERT INTO password_resets (user_id, token_hash, expires_at, used_at, created_at)
+SELECT user_id, token_hash, expires_at, consumed_at, created_at
+FROM tokens
+WHERE purpose = 'reset' AND user_id IS NOT NULL;
+
+INSERT INTO email_changes (user_id, new_email, token_hash, expires_at, created_at)
+SELECT DISTINCT ON (user_id) user_id, payload, token_hash, expires_at, created_at
+FROM tokens
+WHERE purpose = 'email_change' AND user_id IS NOT NULL AND consumed_at IS NULL
+ORDER BY user_id, created_at DESC;
+
+DROP INDEX IF EXISTS tokens_user_id_purpose_idx;
+DROP TABLE IF EXISTS tokens;
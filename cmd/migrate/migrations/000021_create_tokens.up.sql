@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS tokens (
+  id SERIAL PRIMARY KEY,
+  purpose TEXT NOT NULL CHECK (purpose IN ('verify', 'reset', 'invite', 'magic_link', 'email_change')),
+  token_hash TEXT NOT NULL UNIQUE,
+  user_id INTEGER,
+  payload TEXT NOT NULL DEFAULT '',
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  consumed_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS tokens_user_id_purpose_idx ON tokens (user_id, purpose);
+
+-- 000004 的明文驗證 token 只保留 SHA-256 雜湊，寄出的連結仍然有效
+INSERT INTO tokens (purpose, token_hash, user_id, payload, expires_at)
+SELECT 'verify', encode(sha256(convert_to(verify_token, 'UTF8')), 'hex'), id, email, COALESCE(verify_token_expires, NOW())
+FROM users
+WHERE verify_token IS NOT NULL AND NOT verified
+ON CONFLICT (token_hash) DO NOTHING;
+
+INSERT INTO tokens (purpose, token_hash, user_id, expires_at, consumed_at, created_at)
+SELECT 'reset', token_hash, user_id, expires_at, used_at, created_at
+FROM password_resets
+ON CONFLICT (token_hash) DO NOTHING;
+
+INSERT INTO tokens (purpose, token_hash, user_id, payload, expires_at, created_at)
+SELECT 'email_change', token_hash, user_id, new_email, expires_at, created_at
+FROM email_changes
+ON CONFLICT (token_hash) DO NOTHING;
+
+DROP TABLE IF EXISTS password_resets;
+DROP TABLE IF EXISTS email_changes;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS verify_token,
+DROP COLUMN IF EXISTS verify_token_expires;
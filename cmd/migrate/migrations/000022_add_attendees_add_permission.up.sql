@@ -0,0 +1,8 @@
+INSERT INTO permissions (name, description) VALUES
+  ('attendees.add.any', 'Add any user as an attendee of an event')
+ON CONFLICT (name) DO NOTHING;
+
+-- 原本以 attendees.remove.any 替他人報名的角色改用新的權限
+INSERT INTO role_permissions (role, permission)
+SELECT role, 'attendees.add.any' FROM role_permissions WHERE permission = 'attendees.remove.any'
+ON CONFLICT DO NOTHING;
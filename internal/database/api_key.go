@@ -0,0 +1,157 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// APIKeyPrefix marks bearer tokens that are API keys rather than JWTs.
+const APIKeyPrefix = "evk_"
+
+type APIKeyModel struct {
+	DB *sql.DB
+}
+
+type APIKey struct {
+	Id         int        `json:"id"`
+	UserId     int        `json:"-"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// HasScope reports whether the key was granted scope.
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Insert generates a new key for key.UserId and returns its plaintext value.
+// Only the hash is stored; the plaintext cannot be recovered later.
+func (m *APIKeyModel) Insert(key *APIKey) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	secret, err := generateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	plaintext := APIKeyPrefix + secret
+	key.Prefix = plaintext[:len(APIKeyPrefix)+8]
+
+	query := `
+		INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+	err = m.DB.QueryRowContext(ctx, query, key.UserId, key.Name, key.Prefix, hashToken(plaintext), pq.Array(key.Scopes), key.ExpiresAt).Scan(&key.Id, &key.CreatedAt)
+	if err != nil {
+		return "", err
+	}
+
+	return plaintext, nil
+}
+
+// GetByKey looks up an unexpired key by its plaintext value.
+func (m *APIKeyModel) GetByKey(plaintext string) (*APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, name, prefix, scopes, expires_at, last_used_at, created_at
+		FROM api_keys
+		WHERE key_hash = $1 AND (expires_at IS NULL OR expires_at > NOW())
+	`
+
+	var key APIKey
+	err := m.DB.QueryRowContext(ctx, query, hashToken(plaintext)).Scan(
+		&key.Id, &key.UserId, &key.Name, &key.Prefix, pq.Array(&key.Scopes), &key.ExpiresAt, &key.LastUsedAt, &key.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// TouchLastUsed records that the key was just used.
+func (m *APIKeyModel) TouchLastUsed(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, "UPDATE api_keys SET last_used_at = NOW() WHERE id = $1", id)
+	return err
+}
+
+// GetAllForUser lists the user's keys, newest first.
+func (m *APIKeyModel) GetAllForUser(userId int) ([]*APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, name, prefix, scopes, expires_at, last_used_at, created_at
+		FROM api_keys
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*APIKey{}
+
+	for rows.Next() {
+		var key APIKey
+		err := rows.Scan(&key.Id, &key.UserId, &key.Name, &key.Prefix, pq.Array(&key.Scopes), &key.ExpiresAt, &key.LastUsedAt, &key.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, &key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// DeleteAllForUser revokes every key of the user.
+func (m *APIKeyModel) DeleteAllForUser(userId int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, "DELETE FROM api_keys WHERE user_id = $1", userId)
+	return err
+}
+
+// Delete revokes one of the user's keys. It reports whether a key was deleted.
+func (m *APIKeyModel) Delete(id, userId int) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, "DELETE FROM api_keys WHERE id = $1 AND user_id = $2", id, userId)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	return rows == 1, err
+}
@@ -16,33 +16,41 @@ type Attendee struct {
 	UserId  int `json:"user_id"`
 }
 
-func (m *AttendeeModel) Insert(attendee *Attendee) (*Attendee, error) {
+// Insert adds the attendee to an event of the tenant. It returns nil if the
+// event is not visible in the tenant.
+func (m *AttendeeModel) Insert(tenant Tenant, attendee *Attendee) (*Attendee, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
 	query := `
-		INSERT INTO attendees (event_id, user_id) 
-		VALUES ($1, $2) RETURNING id
+		INSERT INTO attendees (event_id, user_id)
+		SELECT e.id, $2 FROM events e
+		WHERE e.id = $1 AND ` + tenantCondition("$3", "$4") + `
+		RETURNING id
 	`
-	err := m.DB.QueryRowContext(ctx, query, attendee.EventId, attendee.UserId).Scan(&attendee.Id)
+	err := m.DB.QueryRowContext(ctx, query, attendee.EventId, attendee.UserId, tenant.OrganizationId, tenant.ViewerId).Scan(&attendee.Id)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
 		return nil, err
 	}
 
 	return attendee, nil
 }
 
-func (m *AttendeeModel) GetByEventAndAttendee(eventId, userId int) (*Attendee, error) {
+func (m *AttendeeModel) GetByEventAndAttendee(tenant Tenant, eventId, userId int) (*Attendee, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
 	query := `
-		SELECT * FROM attendees
-		WHERE event_id = $1 AND user_id = $2
-	`
+		SELECT a.id, a.user_id, a.event_id
+		FROM attendees a
+		JOIN events e ON e.id = a.event_id
+		WHERE a.event_id = $1 AND a.user_id = $2 AND ` + tenantCondition("$3", "$4")
 	var attendee Attendee
 
-	if err := m.DB.QueryRowContext(ctx, query, eventId, userId).Scan(&attendee.Id, &attendee.UserId, &attendee.EventId); err != nil {
+	if err := m.DB.QueryRowContext(ctx, query, eventId, userId, tenant.OrganizationId, tenant.ViewerId).Scan(&attendee.Id, &attendee.UserId, &attendee.EventId); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -51,7 +59,7 @@ func (m *AttendeeModel) GetByEventAndAttendee(eventId, userId int) (*Attendee, e
 	return &attendee, nil
 }
 
-func (m *AttendeeModel) GetAttendeesByEvent(eventId int) ([]*User, error) {
+func (m *AttendeeModel) GetAttendeesByEvent(tenant Tenant, eventId int) ([]*User, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 
 	defer cancel()
@@ -60,9 +68,9 @@ func (m *AttendeeModel) GetAttendeesByEvent(eventId int) ([]*User, error) {
 		SELECT u.id, u.name, u.email
 		FROM users u
 		JOIN attendees a ON u.id = a.user_id
-		WHERE a.event_id = $1
-	`
-	rows, err := m.DB.QueryContext(ctx, query, eventId)
+		JOIN events e ON e.id = a.event_id
+		WHERE a.event_id = $1 AND ` + tenantCondition("$2", "$3")
+	rows, err := m.DB.QueryContext(ctx, query, eventId, tenant.OrganizationId, tenant.ViewerId)
 
 	if err != nil {
 		return nil, err
@@ -83,32 +91,55 @@ func (m *AttendeeModel) GetAttendeesByEvent(eventId int) ([]*User, error) {
 	return users, nil
 }
 
-func (m *AttendeeModel) Delete(userId, eventId int) error {
+func (m *AttendeeModel) Delete(tenant Tenant, userId, eventId int) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
 	query := `
-		DELETE FROM attendees
-		WHERE user_id = $1 AND event_id = $2
+		DELETE FROM attendees a
+		USING events e
+		WHERE e.id = a.event_id AND a.user_id = $1 AND a.event_id = $2 AND e.organization_id = $3
 	`
-	_, err := m.DB.ExecContext(ctx, query, userId, eventId)
+	_, err := m.DB.ExecContext(ctx, query, userId, eventId, tenant.OrganizationId)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (m *AttendeeModel) GetEventsByAttendee(userId int) ([]*Event, error) {
+// GetEventsByAttendee returns the tenant's events the user attends that are
+// visible to the tenant's viewer.
+func (m *AttendeeModel) GetEventsByAttendee(tenant Tenant, userId int) ([]*Event, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
 	query := `
-		SELECT e.id, e.owner_id, e.name, e.description, e.date, e.location
+		SELECT ` + eventColumns + `
+		FROM events e
+		JOIN attendees a ON e.id = a.event_id
+		WHERE a.user_id = $1 AND ` + tenantCondition("$2", "$3")
+
+	return m.queryAttendedEvents(ctx, query, userId, tenant.OrganizationId, tenant.ViewerId)
+}
+
+// GetAllEventsForUser returns the events the user attends in every
+// organization, for the user's own data export.
+func (m *AttendeeModel) GetAllEventsForUser(userId int) ([]*Event, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT ` + eventColumns + `
 		FROM events e
 		JOIN attendees a ON e.id = a.event_id
 		WHERE a.user_id = $1
 	`
-	rows, err := m.DB.QueryContext(ctx, query, userId)
+
+	return m.queryAttendedEvents(ctx, query, userId)
+}
+
+func (m *AttendeeModel) queryAttendedEvents(ctx context.Context, query string, args ...any) ([]*Event, error) {
+	rows, err := m.DB.QueryContext(ctx, query, args...)
 
 	if err != nil {
 		return nil, err
@@ -119,13 +150,12 @@ func (m *AttendeeModel) GetEventsByAttendee(userId int) ([]*Event, error) {
 	var events []*Event
 
 	for rows.Next() {
-		var event Event
-		err := rows.Scan(&event.Id, &event.OwnerId, &event.Name, &event.Description, &event.Date, &event.Location)
+		event, err := scanEvent(rows)
 
 		if err != nil {
 			return nil, err
 		}
-		events = append(events, &event)
+		events = append(events, event)
 	}
 
 	return events, nil
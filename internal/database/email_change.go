@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ErrDuplicateEmail is returned when an email address already belongs to another user.
+var ErrDuplicateEmail = errors.New("email already in use")
+
+type EmailChangeModel struct {
+	DB *sql.DB
+}
+
+// Create records a pending email change for the user and returns the plaintext
+// confirmation token. A user has at most one pending change; requesting a new
+// one replaces the previous request.
+func (m *EmailChangeModel) Create(userId int, newEmail string, ttl time.Duration) (string, error) {
+	tokens := TokenModel{DB: m.DB}
+	return tokens.Issue(TokenPurposeEmailChange, userId, newEmail, ttl)
+}
+
+// Confirm consumes the token and moves the user to the new email address.
+// Because the link was delivered to the new address, the account is marked
+// verified for it and any outstanding verification token is discarded.
+func (m *EmailChangeModel) Confirm(token string) (*User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	change, err := consumeToken(ctx, tx, TokenPurposeEmailChange, token)
+	if err != nil {
+		return nil, err
+	}
+
+	update := `
+		UPDATE users
+		SET email = $1, verified = true
+		WHERE id = $2
+		RETURNING ` + userColumns
+
+	user, err := scanUser(tx.QueryRowContext(ctx, update, change.Payload, change.UserId))
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return nil, ErrDuplicateEmail
+		}
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM tokens WHERE user_id = $1 AND purpose = $2", user.Id, TokenPurposeVerify); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
@@ -4,6 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"time"
+
+	"github.com/lib/pq"
+)
+
+// Event visibilities. Private events are only visible to members of the
+// event's organization.
+const (
+	VisibilityPublic  = "public"
+	VisibilityPrivate = "private"
 )
 
 type EventModel struct {
@@ -11,48 +20,61 @@ type EventModel struct {
 }
 
 type Event struct {
-	Id          int       `json:"id"`
-	OwnerId     int       `json:"-"`
-	Owner       *User     `json:"owner,omitempty"`
-	Name        string    `json:"name" binding:"required,min=3"`
-	Description string    `json:"description" binding:"required,min=10"`
-	Date        time.Time `json:"date" binding:"required"`
-	Location    string    `json:"location" binding:"required,min=3"`
+	Id             int       `json:"id"`
+	OwnerId        int       `json:"-"`
+	Owner          *User     `json:"owner,omitempty"`
+	OrganizationId int       `json:"organization_id"`
+	Name           string    `json:"name" binding:"required,min=3"`
+	Description    string    `json:"description" binding:"required,min=10"`
+	Date           time.Time `json:"date" binding:"required"`
+	Location       string    `json:"location" binding:"required,min=3"`
+	Visibility     string    `json:"visibility" binding:"omitempty,oneof=public private"`
 }
 
-func (m *EventModel) Insert(event *Event) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+// eventWithOwnerColumns lists the columns scanEventWithOwner expects, for
+// events aliased e joined with their owner aliased u.
+const eventWithOwnerColumns = `e.id, e.owner_id, e.organization_id, e.name, e.description, e.date, e.location, e.visibility,
+		       u.id, u.email, u.name, u.role`
 
-	defer cancel()
+// eventColumns lists the columns scanEvent expects, for events aliased e.
+const eventColumns = "e.id, COALESCE(e.owner_id, 0), e.organization_id, e.name, e.description, e.date, e.location, e.visibility"
 
-	query := `
-		INSERT INTO events (owner_id, name, description, date, location)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id
-	`
+func scanEvent(row rowScanner) (*Event, error) {
+	var event Event
 
-	err := m.DB.QueryRowContext(ctx, query, event.OwnerId, event.Name, event.Description, event.Date, event.Location).Scan(&event.Id)
+	err := row.Scan(&event.Id, &event.OwnerId, &event.OrganizationId, &event.Name, &event.Description, &event.Date, &event.Location, &event.Visibility)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return &event, nil
 }
 
-func (m *EventModel) GetAll() ([]*Event, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+// scanEventWithOwner scans an event joined with its owner. Events whose owner
+// deleted their account without deleting the events have no owner.
+func scanEventWithOwner(row rowScanner) (*Event, error) {
+	var event Event
+	var ownerId, userId sql.NullInt64
+	var email, name, role sql.NullString
 
-	defer cancel()
+	err := row.Scan(
+		&event.Id, &ownerId, &event.OrganizationId, &event.Name, &event.Description, &event.Date, &event.Location, &event.Visibility,
+		&userId, &email, &name, &role,
+	)
+	if err != nil {
+		return nil, err
+	}
 
-	query := `
-		SELECT e.id, e.owner_id, e.name, e.description, e.date, e.location,
-		       u.id, u.email, u.name, u.role
-		FROM events e
-		LEFT JOIN users u ON e.owner_id = u.id
-	`
+	event.OwnerId = int(ownerId.Int64)
+	if userId.Valid {
+		event.Owner = &User{Id: int(userId.Int64), Email: email.String, Name: name.String, Role: role.String}
+	}
 
-	rows, err := m.DB.QueryContext(ctx, query)
+	return &event, nil
+}
 
+func (m *EventModel) queryEvents(ctx context.Context, scan func(rowScanner) (*Event, error), query string, args ...any) ([]*Event, error) {
+	rows, err := m.DB.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -62,19 +84,11 @@ func (m *EventModel) GetAll() ([]*Event, error) {
 	events := []*Event{}
 
 	for rows.Next() {
-		var event Event
-		var owner User
-
-		err := rows.Scan(
-			&event.Id, &event.OwnerId, &event.Name, &event.Description, &event.Date, &event.Location,
-			&owner.Id, &owner.Email, &owner.Name, &owner.Role,
-		)
+		event, err := scan(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		event.Owner = &owner
-		events = append(events, &event)
+		events = append(events, event)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -84,25 +98,74 @@ func (m *EventModel) GetAll() ([]*Event, error) {
 	return events, nil
 }
 
-func (m *EventModel) Get(id int) (*Event, error) {
+// GetByOwner returns the events owned by the user in every organization, for
+// the user's own data export.
+func (m *EventModel) GetByOwner(ownerId int) ([]*Event, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
 	query := `
-		SELECT e.id, e.owner_id, e.name, e.description, e.date, e.location,
-		       u.id, u.email, u.name, u.role
+		SELECT ` + eventColumns + `
 		FROM events e
-		LEFT JOIN users u ON e.owner_id = u.id
-		WHERE e.id = $1
+		WHERE e.owner_id = $1
+		ORDER BY e.date
 	`
 
-	var event Event
-	var owner User
+	return m.queryEvents(ctx, scanEvent, query, ownerId)
+}
 
-	err := m.DB.QueryRowContext(ctx, query, id).Scan(
-		&event.Id, &event.OwnerId, &event.Name, &event.Description, &event.Date, &event.Location,
-		&owner.Id, &owner.Email, &owner.Name, &owner.Role,
-	)
+// Insert creates the event in its organization. Visibility defaults to public.
+func (m *EventModel) Insert(event *Event) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+
+	defer cancel()
+
+	if event.Visibility == "" {
+		event.Visibility = VisibilityPublic
+	}
+
+	query := `
+		INSERT INTO events (owner_id, organization_id, name, description, date, location, visibility)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	err := m.DB.QueryRowContext(ctx, query, event.OwnerId, event.OrganizationId, event.Name, event.Description, event.Date, event.Location, event.Visibility).Scan(&event.Id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetAll returns the tenant's events visible to its viewer.
+func (m *EventModel) GetAll(tenant Tenant) ([]*Event, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+
+	defer cancel()
+
+	query := `
+		SELECT ` + eventWithOwnerColumns + `
+		FROM events e
+		LEFT JOIN users u ON e.owner_id = u.id
+		WHERE ` + tenantCondition("$1", "$2")
+
+	return m.queryEvents(ctx, scanEventWithOwner, query, tenant.OrganizationId, tenant.ViewerId)
+}
+
+// Get returns the event if it belongs to the tenant and is visible to its
+// viewer, or nil.
+func (m *EventModel) Get(tenant Tenant, id int) (*Event, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT ` + eventWithOwnerColumns + `
+		FROM events e
+		LEFT JOIN users u ON e.owner_id = u.id
+		WHERE e.id = $3 AND ` + tenantCondition("$1", "$2")
+
+	event, err := scanEventWithOwner(m.DB.QueryRowContext(ctx, query, tenant.OrganizationId, tenant.ViewerId, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -110,19 +173,23 @@ func (m *EventModel) Get(id int) (*Event, error) {
 		return nil, err
 	}
 
-	event.Owner = &owner
-	return &event, nil
+	return event, nil
 }
 
-func (m *EventModel) Update(event *Event) error {
+// Update saves the event's details. An empty visibility keeps the current one.
+func (m *EventModel) Update(tenant Tenant, event *Event) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 
 	defer cancel()
 
-	query := "UPDATE events SET name = $1, description = $2, date = $3, location = $4 WHERE id = $5"
-
-	_, err := m.DB.ExecContext(ctx, query, event.Name, event.Description, event.Date, event.Location, event.Id)
+	query := `
+		UPDATE events
+		SET name = $1, description = $2, date = $3, location = $4, visibility = COALESCE(NULLIF($5, ''), visibility)
+		WHERE id = $6 AND organization_id = $7
+		RETURNING visibility
+	`
 
+	err := m.DB.QueryRowContext(ctx, query, event.Name, event.Description, event.Date, event.Location, event.Visibility, event.Id, tenant.OrganizationId).Scan(&event.Visibility)
 	if err != nil {
 		return err
 	}
@@ -130,17 +197,101 @@ func (m *EventModel) Update(event *Event) error {
 	return nil
 }
 
-func (m *EventModel) Delete(id int) error {
+func (m *EventModel) Delete(tenant Tenant, id int) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 
 	defer cancel()
 
-	query := "DELETE FROM events WHERE id = $1"
+	query := "DELETE FROM events WHERE id = $1 AND organization_id = $2"
 
-	_, err := m.DB.ExecContext(ctx, query, id)
+	_, err := m.DB.ExecContext(ctx, query, id, tenant.OrganizationId)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// Relations between a user and an event, as listed by GetForUser.
+const (
+	EventRoleOwner    = "owner"
+	EventRoleAttendee = "attendee"
+	EventRoleHost     = "host"
+)
+
+// UserEvent is an event together with the current user's relations to it.
+type UserEvent struct {
+	*Event
+	Roles    []string `json:"roles"`
+	HostRole string   `json:"host_role,omitempty"`
+}
+
+// GetForUser returns the events the user owns, attends or hosts, limited to
+// the given relations and, when set, to dates in [from, to). Events are
+// ordered by date and listed once with every matching relation.
+func (m *EventModel) GetForUser(userId int, roles []string, from, to *time.Time) ([]*UserEvent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT ` + eventColumns + `, r.role, COALESCE(r.host_role, '')
+		FROM (
+			SELECT id AS event_id, 'owner' AS role, NULL AS host_role FROM events WHERE owner_id = $1
+			UNION ALL
+			SELECT event_id, 'attendee', NULL FROM attendees WHERE user_id = $1
+			UNION ALL
+			SELECT event_id, 'host', role FROM event_hosts WHERE user_id = $1
+		) r
+		JOIN events e ON e.id = r.event_id
+		WHERE r.role = ANY($2)
+		  AND ($3::timestamp IS NULL OR e.date >= $3::timestamp)
+		  AND ($4::timestamp IS NULL OR e.date < $4::timestamp)
+		ORDER BY e.date, e.id, r.role
+	`
+
+	// date 欄位不含時區，以 UTC 比較
+	var fromArg, toArg sql.NullTime
+	if from != nil {
+		fromArg = sql.NullTime{Time: from.UTC(), Valid: true}
+	}
+	if to != nil {
+		toArg = sql.NullTime{Time: to.UTC(), Valid: true}
+	}
+
+	rows, err := m.DB.QueryContext(ctx, query, userId, pq.Array(roles), fromArg, toArg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*UserEvent{}
+	byId := map[int]*UserEvent{}
+
+	for rows.Next() {
+		var event Event
+		var role, hostRole string
+
+		err := rows.Scan(&event.Id, &event.OwnerId, &event.OrganizationId, &event.Name, &event.Description, &event.Date, &event.Location, &event.Visibility, &role, &hostRole)
+		if err != nil {
+			return nil, err
+		}
+
+		userEvent, ok := byId[event.Id]
+		if !ok {
+			userEvent = &UserEvent{Event: &event, Roles: []string{}}
+			byId[event.Id] = userEvent
+			events = append(events, userEvent)
+		}
+
+		userEvent.Roles = append(userEvent.Roles, role)
+		if hostRole != "" {
+			userEvent.HostRole = hostRole
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
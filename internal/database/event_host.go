@@ -0,0 +1,150 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Event host roles, from most to least privileged. Co-hosts can edit the
+// event and manage attendees, check-in staff can only manage attendees, and
+// viewers can see the event and its attendees even when it is private.
+const (
+	HostRoleCoHost  = "co_host"
+	HostRoleCheckIn = "check_in"
+	HostRoleViewer  = "viewer"
+)
+
+type EventHostModel struct {
+	DB *sql.DB
+}
+
+// EventHost is a user who helps the owner run an event.
+type EventHost struct {
+	UserId    int       `json:"user_id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	InvitedBy int       `json:"invited_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// GetRole returns the user's host role on the event, or "" if the user is not
+// one of its hosts.
+func (m *EventHostModel) GetRole(eventId, userId int) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var role string
+	err := m.DB.QueryRowContext(ctx, "SELECT role FROM event_hosts WHERE event_id = $1 AND user_id = $2", eventId, userId).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return role, err
+}
+
+// GetByEvent lists the event's hosts, co-hosts first.
+func (m *EventHostModel) GetByEvent(eventId int) ([]*EventHost, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT u.id, u.name, u.email, eh.role, COALESCE(eh.invited_by, 0), eh.created_at
+		FROM event_hosts eh
+		JOIN users u ON u.id = eh.user_id
+		WHERE eh.event_id = $1
+		ORDER BY CASE eh.role WHEN 'co_host' THEN 0 WHEN 'check_in' THEN 1 ELSE 2 END, u.name
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, eventId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hosts := []*EventHost{}
+
+	for rows.Next() {
+		var host EventHost
+		if err := rows.Scan(&host.UserId, &host.Name, &host.Email, &host.Role, &host.InvitedBy, &host.CreatedAt); err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, &host)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return hosts, nil
+}
+
+// Set makes the user a host of the event with the given role, or changes the
+// role of an existing host.
+func (m *EventHostModel) Set(eventId, userId int, role string, invitedBy int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO event_hosts (event_id, user_id, role, invited_by)
+		VALUES ($1, $2, $3, NULLIF($4, 0))
+		ON CONFLICT (event_id, user_id) DO UPDATE SET role = EXCLUDED.role
+	`
+	_, err := m.DB.ExecContext(ctx, query, eventId, userId, role, invitedBy)
+	return err
+}
+
+// Remove removes the user from the event's hosts. It reports whether the user
+// was a host.
+func (m *EventHostModel) Remove(eventId, userId int) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, "DELETE FROM event_hosts WHERE event_id = $1 AND user_id = $2", eventId, userId)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// TransferOwnership makes newOwnerId the owner of the event. The previous
+// owner stays on as a co-host, and the new owner stops being a host.
+func (m *EventHostModel) TransferOwnership(eventId, newOwnerId int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var previousOwnerId sql.NullInt64
+	err = tx.QueryRowContext(ctx, "SELECT owner_id FROM events WHERE id = $1 FOR UPDATE", eventId).Scan(&previousOwnerId)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE events SET owner_id = $1 WHERE id = $2", newOwnerId, eventId); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM event_hosts WHERE event_id = $1 AND user_id = $2", eventId, newOwnerId); err != nil {
+		return err
+	}
+
+	if previousOwnerId.Valid {
+		query := `
+			INSERT INTO event_hosts (event_id, user_id, role, invited_by)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (event_id, user_id) DO UPDATE SET role = EXCLUDED.role
+		`
+		if _, err := tx.ExecContext(ctx, query, eventId, previousOwnerId.Int64, HostRoleCoHost, newOwnerId); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
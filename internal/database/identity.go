@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// IdentityModel links users to accounts at external identity providers.
+type IdentityModel struct {
+	DB *sql.DB
+}
+
+// OIDCLoginState is the server-side half of an authorization code login: the
+// nonce expected in the ID token and the PKCE code verifier.
+type OIDCLoginState struct {
+	Provider     string
+	Nonce        string
+	CodeVerifier string
+}
+
+// GetUserId returns the user linked to the provider's subject, or 0 if none
+// is linked, and records the login.
+func (m *IdentityModel) GetUserId(provider, subject string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE user_identities
+		SET last_login_at = NOW()
+		WHERE provider = $1 AND subject = $2
+		RETURNING user_id
+	`
+
+	var userId int
+	err := m.DB.QueryRowContext(ctx, query, provider, subject).Scan(&userId)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return userId, err
+}
+
+// Link connects the provider's subject to the user.
+func (m *IdentityModel) Link(userId int, provider, subject, email string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO user_identities (user_id, provider, subject, email, last_login_at)
+		VALUES ($1, $2, $3, $4, NOW())
+	`
+	_, err := m.DB.ExecContext(ctx, query, userId, provider, subject, email)
+	return err
+}
+
+// CreateLoginState stores the state of a login redirect and returns the
+// plaintext state parameter.
+func (m *IdentityModel) CreateLoginState(state *OIDCLoginState, ttl time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if _, err := m.DB.ExecContext(ctx, "DELETE FROM oidc_login_states WHERE expires_at < NOW()"); err != nil {
+		return "", err
+	}
+
+	token, err := generateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	query := `
+		INSERT INTO oidc_login_states (state_hash, provider, nonce, code_verifier, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err = m.DB.ExecContext(ctx, query, hashToken(token), state.Provider, state.Nonce, state.CodeVerifier, time.Now().Add(ttl))
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// ConsumeLoginState returns and deletes the state of a login redirect. It
+// returns ErrInvalidToken if the state is unknown, expired or already used.
+func (m *IdentityModel) ConsumeLoginState(token string) (*OIDCLoginState, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		DELETE FROM oidc_login_states
+		WHERE state_hash = $1 AND expires_at > NOW()
+		RETURNING provider, nonce, code_verifier
+	`
+
+	var state OIDCLoginState
+	if err := m.DB.QueryRowContext(ctx, query, hashToken(token)).Scan(&state.Provider, &state.Nonce, &state.CodeVerifier); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	return &state, nil
+}
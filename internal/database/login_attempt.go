@@ -0,0 +1,167 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// LoginAttemptModel tracks failed logins per key (e.g. "email:..." or "ip:...")
+// in Postgres so throttling holds across API instances.
+type LoginAttemptModel struct {
+	DB *sql.DB
+}
+
+// Lockout is an audit record of a key being locked out after too many failures.
+type Lockout struct {
+	Id          int        `json:"id"`
+	UserId      *int       `json:"user_id"`
+	Key         string     `json:"key"`
+	IP          string     `json:"ip"`
+	FailedCount int        `json:"failed_count"`
+	LockedUntil time.Time  `json:"locked_until"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UnlockedAt  *time.Time `json:"unlocked_at"`
+	UnlockedBy  *int       `json:"unlocked_by"`
+}
+
+// BlockedUntil returns the latest time any of keys is blocked until, or the
+// zero time if none of them is currently blocked.
+func (m *LoginAttemptModel) BlockedUntil(keys ...string) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT MAX(blocked_until)
+		FROM login_attempts
+		WHERE key = ANY($1) AND blocked_until > NOW()
+	`
+
+	var until sql.NullTime
+	if err := m.DB.QueryRowContext(ctx, query, pq.Array(keys)).Scan(&until); err != nil {
+		return time.Time{}, err
+	}
+
+	return until.Time, nil
+}
+
+// RecordFailure increments the failure counter for key and returns the new count.
+// Counters whose last failure is older than window start over from one.
+func (m *LoginAttemptModel) RecordFailure(key string, window time.Duration) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO login_attempts (key, failed_count, last_failed_at)
+		VALUES ($1, 1, NOW())
+		ON CONFLICT (key) DO UPDATE
+		SET failed_count = CASE
+		        WHEN login_attempts.last_failed_at < NOW() - make_interval(secs => $2) THEN 1
+		        ELSE login_attempts.failed_count + 1
+		    END,
+		    last_failed_at = NOW()
+		RETURNING failed_count
+	`
+
+	var count int
+	err := m.DB.QueryRowContext(ctx, query, key, window.Seconds()).Scan(&count)
+	return count, err
+}
+
+// Block prevents further attempts for key until the given time.
+func (m *LoginAttemptModel) Block(key string, until time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, "UPDATE login_attempts SET blocked_until = $1 WHERE key = $2", until, key)
+	return err
+}
+
+// Reset clears the failure counter and any block for key.
+func (m *LoginAttemptModel) Reset(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, "DELETE FROM login_attempts WHERE key = $1", key)
+	return err
+}
+
+// RecordLockout stores an audit entry for a lockout.
+func (m *LoginAttemptModel) RecordLockout(lockout *Lockout) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO account_lockouts (user_id, key, ip, failed_count, locked_until)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	return m.DB.QueryRowContext(ctx, query, lockout.UserId, lockout.Key, lockout.IP, lockout.FailedCount, lockout.LockedUntil).Scan(&lockout.Id, &lockout.CreatedAt)
+}
+
+// Unlock clears the throttling state for key and marks its open lockouts as
+// released by the given admin.
+func (m *LoginAttemptModel) Unlock(key string, adminId int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM login_attempts WHERE key = $1", key); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE account_lockouts
+		SET unlocked_at = NOW(), unlocked_by = $1
+		WHERE key = $2 AND unlocked_at IS NULL AND locked_until > NOW()
+	`
+	if _, err := tx.ExecContext(ctx, query, adminId, key); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetLockouts returns the most recent lockout records, newest first.
+func (m *LoginAttemptModel) GetLockouts(limit int) ([]*Lockout, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, key, ip, failed_count, locked_until, created_at, unlocked_at, unlocked_by
+		FROM account_lockouts
+		ORDER BY created_at DESC
+		LIMIT $1
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lockouts := []*Lockout{}
+
+	for rows.Next() {
+		var lockout Lockout
+		err := rows.Scan(&lockout.Id, &lockout.UserId, &lockout.Key, &lockout.IP, &lockout.FailedCount,
+			&lockout.LockedUntil, &lockout.CreatedAt, &lockout.UnlockedAt, &lockout.UnlockedBy)
+		if err != nil {
+			return nil, err
+		}
+		lockouts = append(lockouts, &lockout)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return lockouts, nil
+}
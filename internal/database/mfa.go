@@ -0,0 +1,158 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type MFAModel struct {
+	DB *sql.DB
+}
+
+// TOTP is a user's authenticator enrollment. It only protects logins once Enabled.
+type TOTP struct {
+	UserId       int
+	Secret       string
+	Enabled      bool
+	LastUsedStep int64
+}
+
+// GetTOTP returns the user's enrollment, or nil if they never started one.
+func (m *MFAModel) GetTOTP(userId int) (*TOTP, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT user_id, totp_secret, enabled_at IS NOT NULL, last_used_step
+		FROM user_mfa
+		WHERE user_id = $1
+	`
+
+	var totp TOTP
+	err := m.DB.QueryRowContext(ctx, query, userId).Scan(&totp.UserId, &totp.Secret, &totp.Enabled, &totp.LastUsedStep)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &totp, nil
+}
+
+// IsEnabled reports whether the user must pass a TOTP challenge to log in.
+func (m *MFAModel) IsEnabled(userId int) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var enabled bool
+	err := m.DB.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM user_mfa WHERE user_id = $1 AND enabled_at IS NOT NULL)", userId).Scan(&enabled)
+	return enabled, err
+}
+
+// StartEnrollment stores a new, not yet enabled secret for the user,
+// replacing any earlier unfinished enrollment.
+func (m *MFAModel) StartEnrollment(userId int, secret string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO user_mfa (user_id, totp_secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET totp_secret = EXCLUDED.totp_secret, last_used_step = 0, created_at = NOW()
+		WHERE user_mfa.enabled_at IS NULL
+	`
+	_, err := m.DB.ExecContext(ctx, query, userId, secret)
+	return err
+}
+
+// Enable activates the user's enrollment, records step as used and replaces
+// their recovery codes with the given ones.
+func (m *MFAModel) Enable(userId int, step int64, recoveryCodes []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "UPDATE user_mfa SET enabled_at = NOW(), last_used_step = $1 WHERE user_id = $2", step, userId); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM mfa_recovery_codes WHERE user_id = $1", userId); err != nil {
+		return err
+	}
+
+	for _, code := range recoveryCodes {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)", userId, hashToken(code)); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UseStep records step as consumed. It returns false if that step (or a later
+// one) was already used, which rejects replayed codes.
+func (m *MFAModel) UseStep(userId int, step int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, "UPDATE user_mfa SET last_used_step = $1 WHERE user_id = $2 AND last_used_step < $1", step, userId)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	return rows == 1, err
+}
+
+// UseRecoveryCode consumes one of the user's recovery codes.
+func (m *MFAModel) UseRecoveryCode(userId int, code string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE mfa_recovery_codes
+		SET used_at = NOW()
+		WHERE id = (
+			SELECT id FROM mfa_recovery_codes
+			WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+			LIMIT 1
+		)
+	`
+	result, err := m.DB.ExecContext(ctx, query, userId, hashToken(code))
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	return rows == 1, err
+}
+
+// Delete removes the user's enrollment and recovery codes.
+func (m *MFAModel) Delete(userId int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM mfa_recovery_codes WHERE user_id = $1", userId); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_mfa WHERE user_id = $1", userId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -3,15 +3,45 @@ package database
 import "database/sql"
 
 type Models struct {
-	Users     UserModel
-	Events    EventModel
-	Attendees AttendeeModel
+	Users          UserModel
+	Events         EventModel
+	Attendees      AttendeeModel
+	PasswordResets PasswordResetModel
+	RefreshTokens  RefreshTokenModel
+	RevokedTokens  RevokedTokenModel
+	EmailChanges   EmailChangeModel
+	LoginAttempts  LoginAttemptModel
+	MFA            MFAModel
+	APIKeys        APIKeyModel
+	Roles          RoleModel
+	Sessions       SessionModel
+	WebAuthn       WebAuthnModel
+	Identities     IdentityModel
+	Organizations  OrganizationModel
+	EventHosts     EventHostModel
+	Profiles       ProfileModel
+	Tokens         TokenModel
 }
 
 func NewModels(db *sql.DB) Models {
 	return Models{
-		Users:     UserModel{DB: db},
-		Events:    EventModel{DB: db},
-		Attendees: AttendeeModel{DB: db},
+		Users:          UserModel{DB: db},
+		Events:         EventModel{DB: db},
+		Attendees:      AttendeeModel{DB: db},
+		PasswordResets: PasswordResetModel{DB: db},
+		RefreshTokens:  RefreshTokenModel{DB: db},
+		RevokedTokens:  RevokedTokenModel{DB: db},
+		EmailChanges:   EmailChangeModel{DB: db},
+		LoginAttempts:  LoginAttemptModel{DB: db},
+		MFA:            MFAModel{DB: db},
+		APIKeys:        APIKeyModel{DB: db},
+		Roles:          RoleModel{DB: db},
+		Sessions:       SessionModel{DB: db},
+		WebAuthn:       WebAuthnModel{DB: db},
+		Identities:     IdentityModel{DB: db},
+		Organizations:  OrganizationModel{DB: db},
+		EventHosts:     EventHostModel{DB: db},
+		Profiles:       ProfileModel{DB: db},
+		Tokens:         TokenModel{DB: db},
 	}
 }
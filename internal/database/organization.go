@@ -0,0 +1,273 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ErrDuplicateSlug is returned when an organization slug is already taken.
+var ErrDuplicateSlug = errors.New("organization slug already in use")
+
+// Organization roles, from most to least privileged.
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+)
+
+type OrganizationModel struct {
+	DB *sql.DB
+}
+
+// Organization is a workspace that owns events. Every user belongs to the
+// default organization, which serves requests that do not name one.
+type Organization struct {
+	Id        int       `json:"id"`
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	IsDefault bool      `json:"is_default"`
+	Role      string    `json:"role,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OrganizationMember is a user's membership in an organization.
+type OrganizationMember struct {
+	UserId   int       `json:"user_id"`
+	Name     string    `json:"name"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// Tenant scopes event and attendee queries to one organization. Private
+// events are only visible when ViewerId is a member of it or hosts the event;
+// 0 means an anonymous viewer. In the default organization, which everyone
+// joins automatically, only owners and admins count as members here.
+type Tenant struct {
+	OrganizationId int
+	ViewerId       int
+}
+
+// tenantCondition restricts events aliased e to the tenant, with the
+// organization and viewer passed as orgArg and viewerArg. Private events are
+// also visible to their owner and hosts.
+func tenantCondition(orgArg, viewerArg string) string {
+	return `e.organization_id = ` + orgArg + ` AND (e.visibility = 'public' OR e.owner_id = ` + viewerArg + ` OR EXISTS (
+			SELECT 1 FROM organization_members om
+			JOIN organizations o ON o.id = om.organization_id
+			WHERE om.organization_id = e.organization_id AND om.user_id = ` + viewerArg + `
+			AND (NOT o.is_default OR om.role <> 'member')
+		) OR EXISTS (
+			SELECT 1 FROM event_hosts eh
+			WHERE eh.event_id = e.id AND eh.user_id = ` + viewerArg + `
+		))`
+}
+
+const organizationColumns = "id, slug, name, is_default, created_at"
+
+// Insert creates the organization with ownerId as its owner.
+func (m *OrganizationModel) Insert(org *Organization, ownerId int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO organizations (slug, name)
+		VALUES ($1, $2)
+		RETURNING id, is_default, created_at
+	`
+	if err := tx.QueryRowContext(ctx, query, org.Slug, org.Name).Scan(&org.Id, &org.IsDefault, &org.CreatedAt); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrDuplicateSlug
+		}
+		return err
+	}
+
+	member := "INSERT INTO organization_members (organization_id, user_id, role) VALUES ($1, $2, $3)"
+	if _, err := tx.ExecContext(ctx, member, org.Id, ownerId, OrgRoleOwner); err != nil {
+		return err
+	}
+
+	org.Role = OrgRoleOwner
+	return tx.Commit()
+}
+
+func (m *OrganizationModel) getOrganization(query string, args ...any) (*Organization, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var org Organization
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&org.Id, &org.Slug, &org.Name, &org.IsDefault, &org.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &org, nil
+}
+
+// GetBySlug returns the organization with the given slug, or nil.
+func (m *OrganizationModel) GetBySlug(slug string) (*Organization, error) {
+	return m.getOrganization("SELECT "+organizationColumns+" FROM organizations WHERE slug = $1", slug)
+}
+
+// GetDefault returns the default organization.
+func (m *OrganizationModel) GetDefault() (*Organization, error) {
+	return m.getOrganization("SELECT " + organizationColumns + " FROM organizations WHERE is_default")
+}
+
+// GetForUser lists the organizations the user belongs to, with the user's role.
+func (m *OrganizationModel) GetForUser(userId int) ([]*Organization, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT o.id, o.slug, o.name, o.is_default, o.created_at, om.role
+		FROM organizations o
+		JOIN organization_members om ON om.organization_id = o.id
+		WHERE om.user_id = $1
+		ORDER BY o.is_default DESC, o.name
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orgs := []*Organization{}
+
+	for rows.Next() {
+		var org Organization
+		if err := rows.Scan(&org.Id, &org.Slug, &org.Name, &org.IsDefault, &org.CreatedAt, &org.Role); err != nil {
+			return nil, err
+		}
+		orgs = append(orgs, &org)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return orgs, nil
+}
+
+// Rename changes the organization's display name.
+func (m *OrganizationModel) Rename(id int, name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, "UPDATE organizations SET name = $1 WHERE id = $2", name, id)
+	return err
+}
+
+// Delete removes the organization together with its events and memberships.
+func (m *OrganizationModel) Delete(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, "DELETE FROM organizations WHERE id = $1 AND NOT is_default", id)
+	return err
+}
+
+// GetMemberRole returns the user's role in the organization, or "" if the
+// user is not a member.
+func (m *OrganizationModel) GetMemberRole(orgId, userId int) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var role string
+	err := m.DB.QueryRowContext(ctx, "SELECT role FROM organization_members WHERE organization_id = $1 AND user_id = $2", orgId, userId).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return role, err
+}
+
+// GetMembers lists the organization's members, owners first.
+func (m *OrganizationModel) GetMembers(orgId int) ([]*OrganizationMember, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT u.id, u.name, u.email, om.role, om.created_at
+		FROM organization_members om
+		JOIN users u ON u.id = om.user_id
+		WHERE om.organization_id = $1
+		ORDER BY CASE om.role WHEN 'owner' THEN 0 WHEN 'admin' THEN 1 ELSE 2 END, u.name
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, orgId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []*OrganizationMember{}
+
+	for rows.Next() {
+		var member OrganizationMember
+		if err := rows.Scan(&member.UserId, &member.Name, &member.Email, &member.Role, &member.JoinedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, &member)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// SetMember adds the user to the organization, or changes their role if they
+// already belong to it.
+func (m *OrganizationModel) SetMember(orgId, userId int, role string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO organization_members (organization_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (organization_id, user_id) DO UPDATE SET role = EXCLUDED.role
+	`
+	_, err := m.DB.ExecContext(ctx, query, orgId, userId, role)
+	return err
+}
+
+// RemoveMember removes the user from the organization. It reports whether
+// the user was a member.
+func (m *OrganizationModel) RemoveMember(orgId, userId int) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, "DELETE FROM organization_members WHERE organization_id = $1 AND user_id = $2", orgId, userId)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// CountOwners returns how many owners the organization has.
+func (m *OrganizationModel) CountOwners(orgId int) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var count int
+	err := m.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM organization_members WHERE organization_id = $1 AND role = $2", orgId, OrgRoleOwner).Scan(&count)
+	return count, err
+}
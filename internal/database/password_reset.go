@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type PasswordResetModel struct {
+	DB *sql.DB
+}
+
+// Create issues a new reset token for the user and returns its plaintext value.
+// Only the hash is stored, and any earlier unused tokens for the user are discarded.
+func (m *PasswordResetModel) Create(userId int, ttl time.Duration) (string, error) {
+	tokens := TokenModel{DB: m.DB}
+	return tokens.Issue(TokenPurposeReset, userId, "", ttl)
+}
+
+// GetUserId returns the user a reset token belongs to without consuming it.
+// It returns ErrInvalidToken if the token is unknown, expired or already used.
+func (m *PasswordResetModel) GetUserId(token string) (int, error) {
+	tokens := TokenModel{DB: m.DB}
+
+	reset, err := tokens.Peek(TokenPurposeReset, token)
+	if err != nil {
+		return 0, err
+	}
+
+	return reset.UserId, nil
+}
+
+// Reset consumes the token and sets the user's password hash in one transaction.
+// All outstanding reset tokens for the user are invalidated afterwards.
+// It returns ErrInvalidToken if the token is unknown, expired or already used.
+func (m *PasswordResetModel) Reset(token, passwordHash string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	reset, err := consumeToken(ctx, tx, TokenPurposeReset, token)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE users SET password = $1 WHERE id = $2", passwordHash, reset.UserId); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM tokens WHERE user_id = $1 AND purpose = $2 AND consumed_at IS NULL", reset.UserId, TokenPurposeReset); err != nil {
+		return 0, err
+	}
+
+	return reset.UserId, tx.Commit()
+}
+
+// DeleteAllForUser invalidates every outstanding reset token for the user,
+// e.g. after the password was changed through another path.
+func (m *PasswordResetModel) DeleteAllForUser(userId int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, "DELETE FROM tokens WHERE user_id = $1 AND purpose = $2 AND consumed_at IS NULL", userId, TokenPurposeReset)
+	return err
+}
@@ -0,0 +1,162 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+type ProfileModel struct {
+	DB *sql.DB
+}
+
+// ProfileLink is a labelled link shown on a user's profile.
+type ProfileLink struct {
+	Label string `json:"label" binding:"required,max=50"`
+	URL   string `json:"url" binding:"required,url,max=500"`
+}
+
+// Profile is the public part of a user account. It never includes the email
+// address.
+type Profile struct {
+	UserId             int           `json:"id"`
+	Name               string        `json:"name"`
+	Bio                string        `json:"bio"`
+	Timezone           string        `json:"timezone"`
+	Locale             string        `json:"locale"`
+	Organization       string        `json:"organization"`
+	Links              []ProfileLink `json:"links"`
+	AvatarKey          string        `json:"-"`
+	AvatarURL          string        `json:"avatar_url,omitempty"`
+	AvatarThumbnailURL string        `json:"avatar_thumbnail_url,omitempty"`
+	MemberSince        time.Time     `json:"member_since"`
+}
+
+// Get returns the profile of an active user, or nil. Users without profile
+// details get empty ones.
+func (m *ProfileModel) Get(userId int) (*Profile, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT u.id, u.name, u.created_at,
+		       COALESCE(p.bio, ''), COALESCE(p.timezone, ''), COALESCE(p.locale, ''), COALESCE(p.organization, ''),
+		       COALESCE(p.links, '[]'), COALESCE(p.avatar_key, '')
+		FROM users u
+		LEFT JOIN user_profiles p ON p.user_id = u.id
+		WHERE u.id = $1 AND u.disabled_at IS NULL AND u.deletion_scheduled_at IS NULL
+	`
+
+	var profile Profile
+	var links []byte
+
+	err := m.DB.QueryRowContext(ctx, query, userId).Scan(
+		&profile.UserId, &profile.Name, &profile.MemberSince,
+		&profile.Bio, &profile.Timezone, &profile.Locale, &profile.Organization,
+		&links, &profile.AvatarKey,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(links, &profile.Links); err != nil {
+		return nil, err
+	}
+
+	return &profile, nil
+}
+
+// Update saves the profile details. The name and avatar are not changed.
+func (m *ProfileModel) Update(profile *Profile) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if profile.Links == nil {
+		profile.Links = []ProfileLink{}
+	}
+
+	links, err := json.Marshal(profile.Links)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO user_profiles (user_id, bio, timezone, locale, organization, links)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id) DO UPDATE
+		SET bio = EXCLUDED.bio, timezone = EXCLUDED.timezone, locale = EXCLUDED.locale,
+		    organization = EXCLUDED.organization, links = EXCLUDED.links, updated_at = NOW()
+	`
+	_, err = m.DB.ExecContext(ctx, query, profile.UserId, profile.Bio, profile.Timezone, profile.Locale, profile.Organization, links)
+	return err
+}
+
+// SetAvatar stores the key of the user's avatar, or clears it if key is
+// empty, and returns the key it replaced ("" if there was none).
+func (m *ProfileModel) SetAvatar(userId int, key string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		WITH previous AS (
+			SELECT avatar_key FROM user_profiles WHERE user_id = $1
+		)
+		INSERT INTO user_profiles (user_id, avatar_key)
+		VALUES ($1, NULLIF($2, ''))
+		ON CONFLICT (user_id) DO UPDATE SET avatar_key = EXCLUDED.avatar_key, updated_at = NOW()
+		RETURNING COALESCE((SELECT avatar_key FROM previous), '')
+	`
+
+	var previous string
+	err := m.DB.QueryRowContext(ctx, query, userId, key).Scan(&previous)
+	return previous, err
+}
+
+// GetAvatarKey returns the key of the user's avatar, or "" if there is none.
+func (m *ProfileModel) GetAvatarKey(userId int) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var key string
+	err := m.DB.QueryRowContext(ctx, "SELECT COALESCE(avatar_key, '') FROM user_profiles WHERE user_id = $1", userId).Scan(&key)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return key, err
+}
+
+// GetAvatarKeysDueForDeletion returns the avatar keys of the accounts that
+// the next PurgeDueDeletions will delete, so their files can be removed too.
+func (m *ProfileModel) GetAvatarKeysDueForDeletion() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT p.avatar_key
+		FROM user_profiles p
+		JOIN users u ON u.id = p.user_id
+		WHERE u.deletion_scheduled_at <= NOW() AND p.avatar_key IS NOT NULL
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
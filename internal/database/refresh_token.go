@@ -0,0 +1,119 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrTokenReuse is returned when an already rotated refresh token is presented again.
+// The whole token family is revoked before it is returned.
+var ErrTokenReuse = errors.New("refresh token reuse detected")
+
+type RefreshTokenModel struct {
+	DB *sql.DB
+}
+
+// Create issues a refresh token for the session that starts a new token family
+// and returns its plaintext value.
+func (m *RefreshTokenModel) Create(userId, sessionId int, ttl time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	familyId, err := generateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	token, err := generateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	query := `
+		INSERT INTO refresh_tokens (user_id, session_id, family_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	if _, err := m.DB.ExecContext(ctx, query, userId, sessionId, familyId, hashToken(token), time.Now().Add(ttl)); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// Rotate exchanges a refresh token for a new one in the same family and
+// returns the user and session it belongs to. Presenting a token that was
+// already rotated or revoked revokes the whole family and its session and
+// returns ErrTokenReuse.
+func (m *RefreshTokenModel) Rotate(token string, ttl time.Duration) (int, int, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, "", err
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT id, user_id, session_id, family_id, expires_at, revoked_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+		FOR UPDATE
+	`
+
+	var id, userId, sessionId int
+	var familyId string
+	var expiresAt time.Time
+	var revokedAt sql.NullTime
+
+	err = tx.QueryRowContext(ctx, query, hashToken(token)).Scan(&id, &userId, &sessionId, &familyId, &expiresAt, &revokedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, 0, "", ErrInvalidToken
+		}
+		return 0, 0, "", err
+	}
+
+	if revokedAt.Valid {
+		// 已被輪替過的 token 再次出現，代表可能遭竊，整個 family 一併撤銷
+		if _, err := tx.ExecContext(ctx, "UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL", familyId); err != nil {
+			return 0, 0, "", err
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE sessions SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL", sessionId); err != nil {
+			return 0, 0, "", err
+		}
+		if err := tx.Commit(); err != nil {
+			return 0, 0, "", err
+		}
+		return 0, 0, "", ErrTokenReuse
+	}
+
+	if time.Now().After(expiresAt) {
+		return 0, 0, "", ErrInvalidToken
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE refresh_tokens SET revoked_at = NOW() WHERE id = $1", id); err != nil {
+		return 0, 0, "", err
+	}
+
+	newToken, err := generateSecureToken()
+	if err != nil {
+		return 0, 0, "", err
+	}
+
+	insert := `
+		INSERT INTO refresh_tokens (user_id, session_id, family_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	if _, err := tx.ExecContext(ctx, insert, userId, sessionId, familyId, hashToken(newToken), time.Now().Add(ttl)); err != nil {
+		return 0, 0, "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, "", err
+	}
+
+	return userId, sessionId, newToken, nil
+}
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// RevokedTokenModel is the denylist of access token IDs (jti) that were
+// revoked before their natural expiry.
+type RevokedTokenModel struct {
+	DB *sql.DB
+}
+
+// Add denylists jti until expiresAt. Entries that have already expired are
+// pruned at the same time since their tokens are rejected anyway.
+func (m *RevokedTokenModel) Add(jti string, expiresAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if _, err := m.DB.ExecContext(ctx, "DELETE FROM revoked_tokens WHERE expires_at < NOW()"); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO revoked_tokens (jti, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (jti) DO NOTHING
+	`
+	_, err := m.DB.ExecContext(ctx, query, jti, expiresAt)
+	return err
+}
+
+func (m *RevokedTokenModel) IsRevoked(jti string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var exists bool
+	err := m.DB.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)", jti).Scan(&exists)
+	return exists, err
+}
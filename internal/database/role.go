@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type RoleModel struct {
+	DB *sql.DB
+}
+
+type Role struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	IsDefault   bool     `json:"is_default"`
+	Permissions []string `json:"permissions"`
+}
+
+// GetAllPermissions returns the permissions granted to each role, keyed by role name.
+func (m *RoleModel) GetAllPermissions() (map[string][]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, "SELECT role, permission FROM role_permissions")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := make(map[string][]string)
+
+	for rows.Next() {
+		var role, permission string
+		if err := rows.Scan(&role, &permission); err != nil {
+			return nil, err
+		}
+		permissions[role] = append(permissions[role], permission)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+// GetAll returns every role along with its permissions.
+func (m *RoleModel) GetAll() ([]*Role, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT r.name, r.description, r.is_default, COALESCE(array_agg(rp.permission ORDER BY rp.permission) FILTER (WHERE rp.permission IS NOT NULL), '{}')
+		FROM roles r
+		LEFT JOIN role_permissions rp ON rp.role = r.name
+		GROUP BY r.name, r.description, r.is_default
+		ORDER BY r.name
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []*Role{}
+
+	for rows.Next() {
+		var role Role
+		if err := rows.Scan(&role.Name, &role.Description, &role.IsDefault, pq.Array(&role.Permissions)); err != nil {
+			return nil, err
+		}
+		roles = append(roles, &role)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// Exists reports whether a role with the given name exists.
+func (m *RoleModel) Exists(name string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var exists bool
+	err := m.DB.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM roles WHERE name = $1)", name).Scan(&exists)
+	return exists, err
+}
+
+// DefaultName returns the name of the role given to new users.
+func (m *RoleModel) DefaultName() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var name string
+	err := m.DB.QueryRowContext(ctx, "SELECT name FROM roles WHERE is_default").Scan(&name)
+	return name, err
+}
+
+// RoleMember is a user holding a role.
+type RoleMember struct {
+	UserId int
+	Name   string
+}
+
+// GetMembers lists the users holding role, ordered by ID.
+func (m *RoleModel) GetMembers(role string) ([]RoleMember, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, "SELECT id, name FROM users WHERE role = $1 ORDER BY id", role)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []RoleMember{}
+
+	for rows.Next() {
+		var member RoleMember
+		if err := rows.Scan(&member.UserId, &member.Name); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
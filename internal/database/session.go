@@ -0,0 +1,179 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type SessionModel struct {
+	DB *sql.DB
+}
+
+// Session is one signed-in device. Access tokens carry the session id and
+// refresh tokens belong to it, so revoking the session logs the device out.
+type Session struct {
+	Id         int        `json:"id"`
+	UserId     int        `json:"-"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	RevokedAt  *time.Time `json:"-"`
+}
+
+// IsActive reports whether the session has not been revoked.
+func (s *Session) IsActive() bool {
+	return s.RevokedAt == nil
+}
+
+// Create starts a session for the user.
+func (m *SessionModel) Create(userId int, userAgent, ip string) (*Session, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	session := &Session{UserId: userId, UserAgent: userAgent, IP: ip}
+
+	query := `
+		INSERT INTO sessions (user_id, user_agent, ip)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, last_seen_at
+	`
+	err := m.DB.QueryRowContext(ctx, query, userId, userAgent, ip).Scan(&session.Id, &session.CreatedAt, &session.LastSeenAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// Get returns the session, or nil if it does not exist.
+func (m *SessionModel) Get(id int) (*Session, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, user_agent, ip, created_at, last_seen_at, revoked_at
+		FROM sessions
+		WHERE id = $1
+	`
+
+	var session Session
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&session.Id, &session.UserId, &session.UserAgent, &session.IP,
+		&session.CreatedAt, &session.LastSeenAt, &session.RevokedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// Touch records that the session was just used from ip.
+func (m *SessionModel) Touch(id int, ip string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, "UPDATE sessions SET last_seen_at = NOW(), ip = $1 WHERE id = $2", ip, id)
+	return err
+}
+
+// GetActiveForUser lists the user's sessions that have not been revoked, most recently used first.
+func (m *SessionModel) GetActiveForUser(userId int) ([]*Session, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, user_agent, ip, created_at, last_seen_at, revoked_at
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL
+		ORDER BY last_seen_at DESC
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*Session{}
+
+	for rows.Next() {
+		var session Session
+		err := rows.Scan(
+			&session.Id, &session.UserId, &session.UserAgent, &session.IP,
+			&session.CreatedAt, &session.LastSeenAt, &session.RevokedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, &session)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// Revoke revokes one of the user's sessions and its refresh tokens. It reports
+// whether an active session was found.
+func (m *SessionModel) Revoke(id, userId int) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "UPDATE sessions SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL", id, userId)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	// 只有確認 session 屬於該用戶時才撤銷其 refresh token
+	if affected == 0 {
+		return false, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE refresh_tokens SET revoked_at = NOW() WHERE session_id = $1 AND user_id = $2 AND revoked_at IS NULL", id, userId); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// RevokeAllForUser revokes every session of the user except keepId (0 keeps
+// none), together with their refresh tokens.
+func (m *SessionModel) RevokeAllForUser(userId, keepId int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "UPDATE sessions SET revoked_at = NOW() WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL", userId, keepId); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND session_id <> $2 AND revoked_at IS NULL", userId, keepId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
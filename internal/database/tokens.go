@@ -0,0 +1,171 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// ErrInvalidToken is returned when a single-use token is unknown, expired or already used.
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// Token purposes. A token only works for the purpose it was issued for.
+const (
+	TokenPurposeVerify      = "verify"
+	TokenPurposeReset       = "reset"
+	TokenPurposeInvite      = "invite"
+	TokenPurposeMagicLink   = "magic_link"
+	TokenPurposeEmailChange = "email_change"
+)
+
+type TokenModel struct {
+	DB *sql.DB
+}
+
+// Token is a single-use token emailed to a user. Only the SHA-256 hash of its
+// plaintext value is stored. Payload holds purpose-specific data, such as the
+// address an email verification or email change was sent to.
+type Token struct {
+	Id         int
+	Purpose    string
+	UserId     int
+	Payload    string
+	ExpiresAt  time.Time
+	ConsumedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// tokenQuerier is implemented by *sql.DB and *sql.Tx, so that tokens can be
+// issued and consumed inside a caller's transaction.
+type tokenQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// generateSecureToken returns a random URL-safe token suitable for emailing to users.
+func generateSecureToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// hashToken returns the SHA-256 hex digest that is stored in place of the plaintext token.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+const tokenColumns = "id, purpose, COALESCE(user_id, 0), payload, expires_at, consumed_at, created_at"
+
+func scanToken(row rowScanner) (*Token, error) {
+	var token Token
+
+	err := row.Scan(&token.Id, &token.Purpose, &token.UserId, &token.Payload, &token.ExpiresAt, &token.ConsumedAt, &token.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// issueToken stores a new token and returns its plaintext value. Earlier
+// tokens of the same purpose for the user are discarded, so only the latest
+// one works.
+func issueToken(ctx context.Context, q tokenQuerier, purpose string, userId int, payload string, ttl time.Duration) (string, error) {
+	token, err := generateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	if userId != 0 {
+		if _, err := q.ExecContext(ctx, "DELETE FROM tokens WHERE user_id = $1 AND purpose = $2", userId, purpose); err != nil {
+			return "", err
+		}
+	}
+
+	query := `
+		INSERT INTO tokens (purpose, token_hash, user_id, payload, expires_at)
+		VALUES ($1, $2, NULLIF($3, 0), $4, $5)
+	`
+	if _, err := q.ExecContext(ctx, query, purpose, hashToken(token), userId, payload, time.Now().Add(ttl)); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// consumeToken marks the token used and returns it. Concurrent requests
+// cannot both consume the same token.
+func consumeToken(ctx context.Context, q tokenQuerier, purpose, token string) (*Token, error) {
+	query := `
+		UPDATE tokens
+		SET consumed_at = NOW()
+		WHERE token_hash = $1 AND purpose = $2 AND consumed_at IS NULL AND expires_at > NOW()
+		RETURNING ` + tokenColumns
+
+	return scanToken(q.QueryRowContext(ctx, query, hashToken(token), purpose))
+}
+
+// Issue creates a token for the given purpose and returns its plaintext
+// value. userId may be 0 for tokens sent to someone without an account yet,
+// such as invitations.
+func (m *TokenModel) Issue(purpose string, userId int, payload string, ttl time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	token, err := issueToken(ctx, tx, purpose, userId, payload, ttl)
+	if err != nil {
+		return "", err
+	}
+
+	return token, tx.Commit()
+}
+
+// Peek returns a usable token without consuming it. It returns
+// ErrInvalidToken if the token is unknown, expired or already used.
+func (m *TokenModel) Peek(purpose, token string) (*Token, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT ` + tokenColumns + `
+		FROM tokens
+		WHERE token_hash = $1 AND purpose = $2 AND consumed_at IS NULL AND expires_at > NOW()
+	`
+
+	return scanToken(m.DB.QueryRowContext(ctx, query, hashToken(token), purpose))
+}
+
+// Consume uses up the token and returns it. It returns ErrInvalidToken if the
+// token is unknown, expired or already used.
+func (m *TokenModel) Consume(purpose, token string) (*Token, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return consumeToken(ctx, m.DB, purpose, token)
+}
+
+// RevokeAllForUser discards the user's outstanding tokens of the given purpose.
+func (m *TokenModel) RevokeAllForUser(userId int, purpose string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, "DELETE FROM tokens WHERE user_id = $1 AND purpose = $2 AND consumed_at IS NULL", userId, purpose)
+	return err
+}
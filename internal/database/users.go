@@ -2,42 +2,64 @@ package database
 
 import (
 	"context"
-	"crypto/rand"
 	"database/sql"
-	"encoding/base64"
+	"errors"
 	"fmt"
 	"time"
+
+	"github.com/lib/pq"
 )
 
+// ErrDuplicateExternalId is returned when an external ID already belongs to another user.
+var ErrDuplicateExternalId = errors.New("external ID already in use")
+
 type UserModel struct {
 	DB *sql.DB
 }
 
 type User struct {
-	Id                 int       `json:"id"`
-	Email              string    `json:"email"`
-	Name               string    `json:"name"`
-	Password           string    `json:"-"`
-	Role               string    `json:"role"`
-	Verified           bool      `json:"verified"`
-	VerifyToken        string    `json:"verify_token"`
-	VerifyTokenExpires time.Time `json:"verify_token_expires"`
+	Id                  int        `json:"id"`
+	Email               string     `json:"email"`
+	Name                string     `json:"name"`
+	Password            string     `json:"-"`
+	Role                string     `json:"role"`
+	Verified            bool       `json:"verified"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+	DeletionMode        string     `json:"deletion_mode,omitempty"`
+	ExternalId          string     `json:"external_id,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
 }
 
-func generateVerifyToken() string {
-	bytes := make([]byte, 12)
-	_, err := rand.Read(bytes)
+// userColumns lists the columns scanUser expects, in order.
+const userColumns = "id, email, name, password, role, verified, disabled_at, deletion_scheduled_at, COALESCE(deletion_mode, ''), COALESCE(external_id, ''), created_at"
 
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUser(row rowScanner) (*User, error) {
+	var user User
+
+	err := row.Scan(
+		&user.Id, &user.Email, &user.Name, &user.Password, &user.Role, &user.Verified,
+		&user.DisabledAt, &user.DeletionScheduledAt, &user.DeletionMode, &user.ExternalId, &user.CreatedAt,
+	)
 	if err != nil {
-		fmt.Println("Failed to generate random token:", err)
-		return ""
+		return nil, err
 	}
-	return base64.URLEncoding.EncodeToString(bytes)
+
+	return &user, nil
+}
+
+// IsDisabled reports whether an administrator has disabled the account.
+func (u *User) IsDisabled() bool {
+	return u.DisabledAt != nil
 }
 
 // Insert adds a new user to the database.
 // @Summary Add a new user
-// @Description Insert a new user into the database with verification details.
+// @Description Insert a new, unverified user into the database.
 // @Tags User
 // @Param user body User true "User data"
 // @Success 200 {object} User
@@ -48,36 +70,38 @@ func (m *UserModel) Insert(user *User) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	// 預設產生未驗證狀態
+	// 預設產生未驗證狀態，驗證 token 由 TokenModel 另外核發
 	user.Verified = false
-	user.VerifyToken = generateVerifyToken()                 // 生成隨機驗證 token
-	user.VerifyTokenExpires = time.Now().Add(24 * time.Hour) // 設定驗證期限
 
+	// 未指定角色時使用 roles 表中的預設角色，並同時加入預設組織
 	query := `
-		INSERT INTO users (email, password, name, role, verified, verify_token, verify_token_expires)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id
+		WITH inserted AS (
+			INSERT INTO users (email, password, name, role, verified)
+			VALUES ($1, $2, $3, COALESCE(NULLIF($4, ''), (SELECT name FROM roles WHERE is_default)), $5)
+			RETURNING id, role, created_at
+		), membership AS (
+			INSERT INTO organization_members (organization_id, user_id, role)
+			SELECT o.id, inserted.id, 'member' FROM organizations o, inserted
+			WHERE o.is_default
+		)
+		SELECT id, role, created_at FROM inserted
 	`
-	return m.DB.QueryRowContext(ctx, query, user.Email, user.Password, user.Name, user.Role, user.Verified, user.VerifyToken, user.VerifyTokenExpires).Scan(&user.Id)
+	return m.DB.QueryRowContext(ctx, query, user.Email, user.Password, user.Name, user.Role, user.Verified).Scan(&user.Id, &user.Role, &user.CreatedAt)
 }
 
 func (m *UserModel) getUser(query string, args ...interface{}) (*User, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	var user User
-
-	err := m.DB.QueryRowContext(ctx, query, args...).Scan(
-		&user.Id, &user.Email, &user.Name, &user.Password, &user.Role, &user.Verified, &user.VerifyToken, &user.VerifyTokenExpires,
-	)
-
+	user, err := scanUser(m.DB.QueryRowContext(ctx, query, args...))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, err
 	}
-	return &user, nil
+
+	return user, nil
 }
 
 // Get retrieves a user by ID.
@@ -91,7 +115,7 @@ func (m *UserModel) getUser(query string, args ...interface{}) (*User, error) {
 // @Router /users/{id} [get]
 func (m *UserModel) Get(id int) (*User, error) {
 	query := `
-		SELECT id, email, name, password, role, verified, verify_token, verify_token_expires
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE id = $1
 	`
@@ -109,7 +133,7 @@ func (m *UserModel) Get(id int) (*User, error) {
 // @Router /users/email [get]
 func (m *UserModel) GetByEmail(email string) (*User, error) {
 	query := `
-		SELECT id, email, name, password, role, verified, verify_token, verify_token_expires
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE email = $1
 	`
@@ -144,18 +168,289 @@ func (m *UserModel) Update(id int, name, password string) (*User, error) {
 		SET name = COALESCE(NULLIF($1, ''), name),
 		    password = COALESCE(NULLIF($2, ''), password)
 		WHERE id = $3
-		RETURNING id, email, name, password, role, verified, verify_token, verify_token_expires
+		RETURNING ` + userColumns
+
+	return scanUser(m.DB.QueryRowContext(ctx, query, name, password, id))
+}
+
+// MarkVerified flags the user's email as verified and discards any
+// outstanding verification token.
+func (m *UserModel) MarkVerified(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		WITH discarded AS (
+			DELETE FROM tokens WHERE user_id = $1 AND purpose = $2
+		)
+		UPDATE users
+		SET verified = true
+		WHERE id = $1
 	`
+	_, err := m.DB.ExecContext(ctx, query, id, TokenPurposeVerify)
+	return err
+}
 
-	var user User
-	err = m.DB.QueryRowContext(ctx, query, name, password, id).Scan(
-		&user.Id, &email, &user.Name, &user.Password, &user.Role, &user.Verified, &user.VerifyToken, &user.VerifyTokenExpires,
-	)
+// UserFilter narrows and paginates the user list for administrators.
+type UserFilter struct {
+	Search   string
+	Role     string
+	Verified *bool
+	Disabled *bool
+	Page     int
+	PageSize int
+}
 
+// List returns one page of users matching filter, ordered by ID, and the total
+// number of matching users. Search matches name or email case-insensitively.
+func (m *UserModel) List(filter UserFilter) ([]*User, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	where := `
+		WHERE ($1 = '' OR name ILIKE '%' || $1 || '%' OR email ILIKE '%' || $1 || '%')
+		  AND ($2 = '' OR role = $2)
+		  AND ($3::boolean IS NULL OR verified = $3)
+		  AND ($4::boolean IS NULL OR (disabled_at IS NOT NULL) = $4)
+	`
+	args := []any{filter.Search, filter.Role, filter.Verified, filter.Disabled}
+
+	return m.listWhere(ctx, where, args, (filter.Page-1)*filter.PageSize, filter.PageSize)
+}
+
+// ListMatching returns up to limit users, ordered by ID and skipping the
+// first offset, for which condition holds, and the total number of matching
+// users. condition is a SQL boolean expression over the users columns built
+// by trusted code, with its values passed as args ($1, $2, ...).
+func (m *UserModel) ListMatching(condition string, args []any, offset, limit int) ([]*User, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.listWhere(ctx, "WHERE "+condition, args, offset, limit)
+}
+
+func (m *UserModel) listWhere(ctx context.Context, where string, args []any, offset, limit int) ([]*User, int, error) {
+	var total int
+	if err := m.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM users "+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM users %s ORDER BY id LIMIT $%d OFFSET $%d", userColumns, where, len(args)+1, len(args)+2)
+	rows, err := m.DB.QueryContext(ctx, query, append(args, limit, offset)...)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
+	defer rows.Close()
 
-	user.Email = email
-	return &user, nil
+	users := []*User{}
+
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// ProvisionedUpdate is what a provisioning client changes besides the email,
+// name and external ID.
+type ProvisionedUpdate struct {
+	// PasswordHash replaces the password; empty keeps it.
+	PasswordHash string
+	// Disabled disables or re-enables the account; nil keeps its state.
+	Disabled *bool
+}
+
+// SaveProvisioned stores the email, name and external ID of an account
+// managed by a provisioning client together with update, in one transaction.
+// A changed email is trusted and marked verified. Setting a password or
+// disabling the account revokes all sessions and refresh tokens, and a new
+// password also deletes the user's API keys and invalidates outstanding
+// reset tokens. It returns
+// ErrDuplicateEmail or ErrDuplicateExternalId if another user already has the
+// email or external ID.
+func (m *UserModel) SaveProvisioned(user *User, update ProvisionedUpdate) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE users
+		SET verified = verified OR email <> $1,
+		    email = $1,
+		    name = $2,
+		    external_id = NULLIF($3, ''),
+		    password = COALESCE(NULLIF($4, ''), password),
+		    disabled_at = CASE
+		        WHEN $5::boolean IS NULL THEN disabled_at
+		        WHEN $5 THEN COALESCE(disabled_at, NOW())
+		        ELSE NULL
+		    END
+		WHERE id = $6
+		RETURNING ` + userColumns
+
+	updated, err := scanUser(tx.QueryRowContext(ctx, query, user.Email, user.Name, user.ExternalId, update.PasswordHash, update.Disabled, user.Id))
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			if pqErr.Constraint == "users_external_id_idx" {
+				return ErrDuplicateExternalId
+			}
+			return ErrDuplicateEmail
+		}
+		return err
+	}
+
+	// 新密碼或停用帳號都要讓既有的登入失效；舊的重設連結也不能再用來覆寫新密碼
+	if update.PasswordHash != "" || (update.Disabled != nil && *update.Disabled) {
+		if _, err := tx.ExecContext(ctx, "UPDATE sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL", user.Id); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL", user.Id); err != nil {
+			return err
+		}
+	}
+
+	if update.PasswordHash != "" {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM tokens WHERE user_id = $1 AND purpose = $2 AND consumed_at IS NULL", user.Id, TokenPurposeReset); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM api_keys WHERE user_id = $1", user.Id); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	*user = *updated
+	return nil
+}
+
+// SetRole changes the user's role. The role must exist in the roles table.
+func (m *UserModel) SetRole(id int, role string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, "UPDATE users SET role = $1 WHERE id = $2", role, id)
+	return err
+}
+
+// SetRoles changes the role of several users in one transaction, e.g. when a
+// provisioning client replaces a group's members. roles maps user IDs to role
+// names; the roles must exist in the roles table.
+func (m *UserModel) SetRoles(roles map[int]string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for id, role := range roles {
+		if _, err := tx.ExecContext(ctx, "UPDATE users SET role = $1 WHERE id = $2", role, id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SetDisabled disables or re-enables the account.
+func (m *UserModel) SetDisabled(id int, disabled bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE users
+		SET disabled_at = CASE WHEN $1 THEN COALESCE(disabled_at, NOW()) ELSE NULL END
+		WHERE id = $2
+	`
+	_, err := m.DB.ExecContext(ctx, query, disabled, id)
+	return err
+}
+
+// Delete removes the user and everything that cascades from it.
+func (m *UserModel) Delete(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, "DELETE FROM users WHERE id = $1", id)
+	return err
+}
+
+// Account deletion modes: keep the user's events without an owner, or delete them too.
+const (
+	DeletionModeAnonymize = "anonymize"
+	DeletionModeCascade   = "cascade"
+)
+
+// ScheduleDeletion marks the account for deletion at the given time.
+func (m *UserModel) ScheduleDeletion(id int, at time.Time, mode string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, "UPDATE users SET deletion_scheduled_at = $1, deletion_mode = $2 WHERE id = $3", at, mode, id)
+	return err
+}
+
+// CancelDeletion clears a pending account deletion.
+func (m *UserModel) CancelDeletion(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, "UPDATE users SET deletion_scheduled_at = NULL, deletion_mode = NULL WHERE id = $1", id)
+	return err
+}
+
+// PurgeDueDeletions deletes every account whose grace period has ended and
+// returns how many were deleted. Events owned by accounts in cascade mode are
+// deleted with them; otherwise they stay, without an owner.
+func (m *UserModel) PurgeDueDeletions() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		DELETE FROM events
+		WHERE owner_id IN (
+			SELECT id FROM users
+			WHERE deletion_scheduled_at <= NOW() AND deletion_mode = $1
+		)
+	`
+	if _, err := tx.ExecContext(ctx, query, DeletionModeCascade); err != nil {
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM users WHERE deletion_scheduled_at <= NOW()")
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(deleted), tx.Commit()
 }
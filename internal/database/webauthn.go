@@ -0,0 +1,242 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// webAuthnHandleLength is the size of the random user handle given to authenticators.
+const webAuthnHandleLength = 32
+
+type WebAuthnModel struct {
+	DB *sql.DB
+}
+
+// WebAuthnCredential is a registered passkey or security key.
+type WebAuthnCredential struct {
+	Id              int        `json:"id"`
+	UserId          int        `json:"-"`
+	Name            string     `json:"name"`
+	CredentialId    []byte     `json:"-"`
+	PublicKey       []byte     `json:"-"`
+	AttestationType string     `json:"-"`
+	Transports      []string   `json:"transports"`
+	AAGUID          []byte     `json:"-"`
+	SignCount       uint32     `json:"-"`
+	CloneWarning    bool       `json:"-"`
+	BackupEligible  bool       `json:"backup_eligible"`
+	BackupState     bool       `json:"backup_state"`
+	LastUsedAt      *time.Time `json:"last_used_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+const webAuthnCredentialColumns = `id, user_id, name, credential_id, public_key, attestation_type, transports, aaguid,
+	sign_count, clone_warning, backup_eligible, backup_state, last_used_at, created_at`
+
+func scanWebAuthnCredential(row rowScanner) (*WebAuthnCredential, error) {
+	var cred WebAuthnCredential
+	var signCount int64
+
+	err := row.Scan(
+		&cred.Id, &cred.UserId, &cred.Name, &cred.CredentialId, &cred.PublicKey, &cred.AttestationType,
+		pq.Array(&cred.Transports), &cred.AAGUID, &signCount, &cred.CloneWarning, &cred.BackupEligible,
+		&cred.BackupState, &cred.LastUsedAt, &cred.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cred.SignCount = uint32(signCount)
+	return &cred, nil
+}
+
+// GetOrCreateHandle returns the user's WebAuthn user handle, creating a random
+// one the first time.
+func (m *WebAuthnModel) GetOrCreateHandle(userId int) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	handle := make([]byte, webAuthnHandleLength)
+	if _, err := rand.Read(handle); err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO webauthn_users (user_id, handle)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO NOTHING
+	`
+	if _, err := m.DB.ExecContext(ctx, query, userId, handle); err != nil {
+		return nil, err
+	}
+
+	err := m.DB.QueryRowContext(ctx, "SELECT handle FROM webauthn_users WHERE user_id = $1", userId).Scan(&handle)
+	return handle, err
+}
+
+// GetUserIdByHandle returns the user with the given handle, or 0 if there is none.
+func (m *WebAuthnModel) GetUserIdByHandle(handle []byte) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var userId int
+	err := m.DB.QueryRowContext(ctx, "SELECT user_id FROM webauthn_users WHERE handle = $1", handle).Scan(&userId)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return userId, err
+}
+
+// Insert stores a newly registered credential.
+func (m *WebAuthnModel) Insert(cred *WebAuthnCredential) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO webauthn_credentials (user_id, name, credential_id, public_key, attestation_type, transports,
+			aaguid, sign_count, backup_eligible, backup_state)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at
+	`
+
+	return m.DB.QueryRowContext(ctx, query,
+		cred.UserId, cred.Name, cred.CredentialId, cred.PublicKey, cred.AttestationType, pq.Array(cred.Transports),
+		cred.AAGUID, int64(cred.SignCount), cred.BackupEligible, cred.BackupState,
+	).Scan(&cred.Id, &cred.CreatedAt)
+}
+
+// GetAllForUser lists the user's credentials, oldest first.
+func (m *WebAuthnModel) GetAllForUser(userId int) ([]*WebAuthnCredential, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT ` + webAuthnCredentialColumns + `
+		FROM webauthn_credentials
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	creds := []*WebAuthnCredential{}
+
+	for rows.Next() {
+		cred, err := scanWebAuthnCredential(rows)
+		if err != nil {
+			return nil, err
+		}
+		creds = append(creds, cred)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return creds, nil
+}
+
+// RecordLogin stores the sign counter and flags reported by a successful
+// assertion and marks the credential as used.
+func (m *WebAuthnModel) RecordLogin(id int, signCount uint32, cloneWarning, backupState bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE webauthn_credentials
+		SET sign_count = $1, clone_warning = $2, backup_state = $3, last_used_at = NOW()
+		WHERE id = $4
+	`
+	_, err := m.DB.ExecContext(ctx, query, int64(signCount), cloneWarning, backupState, id)
+	return err
+}
+
+// Rename changes the name of one of the user's credentials. It reports whether
+// the credential was found.
+func (m *WebAuthnModel) Rename(id, userId int, name string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, "UPDATE webauthn_credentials SET name = $1 WHERE id = $2 AND user_id = $3", name, id, userId)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// Delete removes one of the user's credentials. It reports whether the
+// credential was found.
+func (m *WebAuthnModel) Delete(id, userId int) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, "DELETE FROM webauthn_credentials WHERE id = $1 AND user_id = $2", id, userId)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// StartCeremony stores the server-side state of a registration or login
+// ceremony and returns the plaintext token that identifies it. userId is 0
+// for discoverable logins, where the user is not known yet.
+func (m *WebAuthnModel) StartCeremony(purpose string, userId int, sessionData []byte, ttl time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if _, err := m.DB.ExecContext(ctx, "DELETE FROM webauthn_ceremonies WHERE expires_at < NOW()"); err != nil {
+		return "", err
+	}
+
+	token, err := generateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	query := `
+		INSERT INTO webauthn_ceremonies (token_hash, purpose, user_id, session_data, expires_at)
+		VALUES ($1, $2, NULLIF($3, 0), $4, $5)
+	`
+	if _, err := m.DB.ExecContext(ctx, query, hashToken(token), purpose, userId, sessionData, time.Now().Add(ttl)); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// FinishCeremony consumes a ceremony and returns its user (0 if none) and
+// session data. It returns ErrInvalidToken if the ceremony is unknown, expired,
+// already finished or was started for another purpose.
+func (m *WebAuthnModel) FinishCeremony(token, purpose string) (int, []byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		DELETE FROM webauthn_ceremonies
+		WHERE token_hash = $1 AND purpose = $2 AND expires_at > NOW()
+		RETURNING COALESCE(user_id, 0), session_data
+	`
+
+	var userId int
+	var sessionData []byte
+	if err := m.DB.QueryRowContext(ctx, query, hashToken(token), purpose).Scan(&userId, &sessionData); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil, ErrInvalidToken
+		}
+		return 0, nil, err
+	}
+
+	return userId, sessionData, nil
+}
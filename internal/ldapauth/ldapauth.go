@@ -0,0 +1,189 @@
+// Package ldapauth authenticates users against an LDAP directory or Active
+// Directory with the usual search-then-bind flow: a service account looks up
+// the user's entry, then the user's own DN and password are bound to check
+// the password.
+package ldapauth
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// ErrInvalidCredentials is returned when the user does not exist in the
+// directory or the password is wrong.
+var ErrInvalidCredentials = errors.New("ldapauth: invalid credentials")
+
+// Config describes how to reach the directory and where to find users.
+type Config struct {
+	// URL is the server address, e.g. ldaps://ad.example.com:636.
+	URL string
+	// BindDN and BindPassword are the service account used to search for
+	// users. Both empty means an anonymous search.
+	BindDN       string
+	BindPassword string
+	// BaseDN is where the user search starts.
+	BaseDN string
+	// UserFilter finds a user by login name; %s is replaced with the escaped
+	// name. Defaults to (mail=%s).
+	UserFilter string
+	// Attributes holding the user's email, display name and group DNs.
+	// Default to mail, cn and memberOf.
+	EmailAttribute string
+	NameAttribute  string
+	GroupAttribute string
+	// StartTLS upgrades an ldap:// connection before binding.
+	StartTLS           bool
+	InsecureSkipVerify bool
+	// Timeout limits dialing and each request. Defaults to 10 seconds.
+	Timeout time.Duration
+}
+
+// Entry is an authenticated directory user.
+type Entry struct {
+	DN     string
+	Email  string
+	Name   string
+	Groups []string
+}
+
+// InGroup reports whether the entry is a direct member of the group with the
+// given DN. DNs are compared case-insensitively.
+func (e *Entry) InGroup(groupDN string) bool {
+	want, err := ldap.ParseDN(groupDN)
+	if err != nil {
+		return false
+	}
+
+	for _, group := range e.Groups {
+		if dn, err := ldap.ParseDN(group); err == nil && dn.EqualFold(want) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Client authenticates against one directory. It opens a new connection per
+// call and is safe for concurrent use.
+type Client struct {
+	config Config
+}
+
+// New returns a Client for config, filling in defaults.
+func New(config Config) (*Client, error) {
+	if config.URL == "" || config.BaseDN == "" {
+		return nil, errors.New("ldapauth: URL and BaseDN are required")
+	}
+
+	if config.UserFilter == "" {
+		config.UserFilter = "(mail=%s)"
+	}
+	if !strings.Contains(config.UserFilter, "%s") {
+		return nil, errors.New("ldapauth: UserFilter must contain %s")
+	}
+	if config.EmailAttribute == "" {
+		config.EmailAttribute = "mail"
+	}
+	if config.NameAttribute == "" {
+		config.NameAttribute = "cn"
+	}
+	if config.GroupAttribute == "" {
+		config.GroupAttribute = "memberOf"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	return &Client{config: config}, nil
+}
+
+// Authenticate checks username and password and returns the user's entry. It
+// returns ErrInvalidCredentials if the user is unknown or the password is
+// wrong; any other error means the directory could not be queried.
+func (c *Client) Authenticate(username, password string) (*Entry, error) {
+	// 空密碼會被多數伺服器當成匿名 bind 而「成功」，必須先擋下
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if c.config.BindDN != "" || c.config.BindPassword != "" {
+		if err := conn.Bind(c.config.BindDN, c.config.BindPassword); err != nil {
+			return nil, fmt.Errorf("ldapauth: service account bind: %w", err)
+		}
+	}
+
+	search := ldap.NewSearchRequest(
+		c.config.BaseDN,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, int(c.config.Timeout.Seconds()), false,
+		fmt.Sprintf(c.config.UserFilter, ldap.EscapeFilter(username)),
+		[]string{c.config.EmailAttribute, c.config.NameAttribute, c.config.GroupAttribute},
+		nil,
+	)
+
+	result, err := conn.Search(search)
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, fmt.Errorf("ldapauth: search user: %w", err)
+	}
+	if result == nil || len(result.Entries) == 0 {
+		return nil, ErrInvalidCredentials
+	}
+	if len(result.Entries) > 1 {
+		return nil, fmt.Errorf("ldapauth: filter matches more than one entry for %q", username)
+	}
+
+	found := result.Entries[0]
+
+	if err := conn.Bind(found.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("ldapauth: user bind: %w", err)
+	}
+
+	return &Entry{
+		DN:     found.DN,
+		Email:  found.GetAttributeValue(c.config.EmailAttribute),
+		Name:   found.GetAttributeValue(c.config.NameAttribute),
+		Groups: found.GetAttributeValues(c.config.GroupAttribute),
+	}, nil
+}
+
+func (c *Client) dial() (*ldap.Conn, error) {
+	u, err := url.Parse(c.config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("ldapauth: parse URL: %w", err)
+	}
+	tlsConfig := &tls.Config{ServerName: u.Hostname(), InsecureSkipVerify: c.config.InsecureSkipVerify}
+
+	conn, err := ldap.DialURL(c.config.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: c.config.Timeout}),
+		ldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ldapauth: dial: %w", err)
+	}
+	conn.SetTimeout(c.config.Timeout)
+
+	if c.config.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("ldapauth: start TLS: %w", err)
+		}
+	}
+
+	return conn, nil
+}
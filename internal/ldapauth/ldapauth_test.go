@@ -0,0 +1,307 @@
+package ldapauth
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// LDAP protocol operations (RFC 4511) handled by testServer.
+const (
+	opBindRequest      = 0
+	opBindResponse     = 1
+	opUnbindRequest    = 2
+	opSearchRequest    = 3
+	opSearchResultItem = 4
+	opSearchResultDone = 5
+)
+
+type testEntry struct {
+	dn         string
+	password   string
+	attributes map[string][]string
+}
+
+// testServer is an in-process LDAP server that supports simple binds and
+// equality-filter searches over a fixed set of entries.
+type testServer struct {
+	listener net.Listener
+	entries  []testEntry
+
+	mu    sync.Mutex
+	binds []string
+}
+
+func newTestServer(t *testing.T, entries ...testEntry) *testServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &testServer{listener: listener, entries: entries}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *testServer) URL() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+// boundDNs returns the DNs of every bind the server received, in order.
+func (s *testServer) boundDNs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.binds)
+}
+
+func (s *testServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+
+		messageId := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+
+		var responses []*ber.Packet
+		switch op.Tag {
+		case opBindRequest:
+			responses = []*ber.Packet{s.bind(op)}
+		case opSearchRequest:
+			responses = s.search(op)
+		case opUnbindRequest:
+			return
+		default:
+			return
+		}
+
+		for _, response := range responses {
+			message := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+			message.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageId, "MessageID"))
+			message.AppendChild(response)
+
+			if _, err := conn.Write(message.Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *testServer) bind(op *ber.Packet) *ber.Packet {
+	dn := op.Children[1].Value.(string)
+	password := op.Children[2].Data.String()
+
+	s.mu.Lock()
+	s.binds = append(s.binds, dn)
+	s.mu.Unlock()
+
+	code := uint16(ldap.LDAPResultInvalidCredentials)
+	for _, entry := range s.entries {
+		if strings.EqualFold(entry.dn, dn) && entry.password == password {
+			code = ldap.LDAPResultSuccess
+		}
+	}
+
+	return ldapResult(opBindResponse, code)
+}
+
+func (s *testServer) search(op *ber.Packet) []*ber.Packet {
+	filter, err := ldap.DecompileFilter(op.Children[6])
+	if err != nil {
+		return []*ber.Packet{ldapResult(opSearchResultDone, ldap.LDAPResultProtocolError)}
+	}
+
+	var responses []*ber.Packet
+
+	for _, entry := range s.entries {
+		if !entry.matches(filter) {
+			continue
+		}
+
+		item := ber.Encode(ber.ClassApplication, ber.TypeConstructed, opSearchResultItem, nil, "Search Result Entry")
+		item.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.dn, "DN"))
+
+		attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+		for name, values := range entry.attributes {
+			attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+			attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+
+			set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+			for _, value := range values {
+				set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+			}
+			attribute.AppendChild(set)
+			attributes.AppendChild(attribute)
+		}
+		item.AppendChild(attributes)
+
+		responses = append(responses, item)
+	}
+
+	return append(responses, ldapResult(opSearchResultDone, ldap.LDAPResultSuccess))
+}
+
+// matches supports the single equality filters used by Client, such as
+// (mail=alice@example.com).
+func (e testEntry) matches(filter string) bool {
+	name, value, ok := strings.Cut(strings.TrimSuffix(strings.TrimPrefix(filter, "("), ")"), "=")
+	if !ok {
+		return false
+	}
+
+	for attribute, values := range e.attributes {
+		if strings.EqualFold(attribute, name) && slices.Contains(values, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func ldapResult(op int, code uint16) *ber.Packet {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ber.Tag(op), nil, "Result")
+	result.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "Result Code"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+	return result
+}
+
+const (
+	serviceDN  = "cn=service,dc=example,dc=com"
+	aliceDN    = "uid=alice,ou=people,dc=example,dc=com"
+	adminsDN   = "cn=event-admins,ou=groups,dc=example,dc=com"
+	aliceEmail = "alice@example.com"
+)
+
+func newDirectory(t *testing.T) *testServer {
+	return newTestServer(t,
+		testEntry{dn: serviceDN, password: "service-secret"},
+		testEntry{
+			dn:       aliceDN,
+			password: "alice-secret",
+			attributes: map[string][]string{
+				"mail":     {aliceEmail},
+				"cn":       {"Alice Example"},
+				"memberOf": {adminsDN, "cn=staff,ou=groups,dc=example,dc=com"},
+			},
+		},
+	)
+}
+
+func newTestClient(t *testing.T, server *testServer, bindPassword string) *Client {
+	t.Helper()
+
+	client, err := New(Config{
+		URL:          server.URL(),
+		BindDN:       serviceDN,
+		BindPassword: bindPassword,
+		BaseDN:       "ou=people,dc=example,dc=com",
+		Timeout:      5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client
+}
+
+func TestAuthenticate(t *testing.T) {
+	server := newDirectory(t)
+	client := newTestClient(t, server, "service-secret")
+
+	entry, err := client.Authenticate(aliceEmail, "alice-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if entry.DN != aliceDN || entry.Email != aliceEmail || entry.Name != "Alice Example" {
+		t.Errorf("got entry %+v", entry)
+	}
+
+	if !entry.InGroup("CN=Event-Admins,OU=Groups,DC=example,DC=com") {
+		t.Errorf("expected membership of %s, got groups %v", adminsDN, entry.Groups)
+	}
+
+	if entry.InGroup("cn=other,ou=groups,dc=example,dc=com") {
+		t.Error("unexpected membership of cn=other")
+	}
+
+	// 先以服務帳號搜尋，再以用戶自己的 DN bind 驗證密碼
+	if binds := server.boundDNs(); !slices.Equal(binds, []string{serviceDN, aliceDN}) {
+		t.Errorf("got binds %v", binds)
+	}
+}
+
+func TestAuthenticateInvalidCredentials(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		password string
+	}{
+		{"wrong password", aliceEmail, "wrong"},
+		{"unknown user", "bob@example.com", "alice-secret"},
+		{"empty password", aliceEmail, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newDirectory(t)
+			client := newTestClient(t, server, "service-secret")
+
+			if _, err := client.Authenticate(tt.username, tt.password); !errors.Is(err, ErrInvalidCredentials) {
+				t.Fatalf("got error %v, want %v", err, ErrInvalidCredentials)
+			}
+		})
+	}
+}
+
+func TestAuthenticateServiceAccountFailure(t *testing.T) {
+	server := newDirectory(t)
+	client := newTestClient(t, server, "wrong")
+
+	_, err := client.Authenticate(aliceEmail, "alice-secret")
+	if err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("got error %v, want a directory error", err)
+	}
+}
+
+func TestAuthenticateUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	client, err := New(Config{URL: fmt.Sprintf("ldap://%s", addr), BaseDN: "dc=example,dc=com", Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.Authenticate(aliceEmail, "alice-secret"); err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("got error %v, want a dial error", err)
+	}
+}
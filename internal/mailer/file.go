@@ -0,0 +1,33 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileMailer writes each message as an .eml file into Dir instead of sending it.
+// It is intended for local development and tests.
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &FileMailer{Dir: dir, From: from}, nil
+}
+
+func (m *FileMailer) Send(msg *Message) error {
+	data, err := buildMIME(m.From, msg)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405"), randomID()[:8])
+	return os.WriteFile(filepath.Join(m.Dir, name), data, 0o644)
+}
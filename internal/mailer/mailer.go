@@ -0,0 +1,134 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	htmltemplate "html/template"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+	"text/template"
+	"time"
+)
+
+//go:embed "templates"
+var templateFS embed.FS
+
+// Message is a rendered email with a plain-text body and an optional HTML alternative.
+type Message struct {
+	To       string
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+// Mailer delivers rendered messages.
+type Mailer interface {
+	Send(msg *Message) error
+}
+
+// Render builds a Message for recipient from one of the embedded templates.
+// Each template file defines "subject", "plainBody" and "htmlBody" blocks.
+func Render(recipient, templateFile string, data any) (*Message, error) {
+	path := "templates/" + templateFile
+
+	tmpl, err := template.New("email").ParseFS(templateFS, path)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := new(bytes.Buffer)
+	if err := tmpl.ExecuteTemplate(subject, "subject", data); err != nil {
+		return nil, err
+	}
+
+	plainBody := new(bytes.Buffer)
+	if err := tmpl.ExecuteTemplate(plainBody, "plainBody", data); err != nil {
+		return nil, err
+	}
+
+	htmlTmpl, err := htmltemplate.New("email").ParseFS(templateFS, path)
+	if err != nil {
+		return nil, err
+	}
+
+	htmlBody := new(bytes.Buffer)
+	if err := htmlTmpl.ExecuteTemplate(htmlBody, "htmlBody", data); err != nil {
+		return nil, err
+	}
+
+	return &Message{
+		To:       recipient,
+		Subject:  strings.TrimSpace(subject.String()),
+		TextBody: plainBody.String(),
+		HTMLBody: htmlBody.String(),
+	}, nil
+}
+
+// buildMIME encodes msg as a multipart/alternative RFC 5322 message.
+func buildMIME(from string, msg *Message) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=UTF-8", msg.TextBody},
+		{"text/html; charset=UTF-8", msg.HTMLBody},
+	}
+
+	for _, p := range parts {
+		if p.content == "" {
+			continue
+		}
+
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType},
+			"Content-Transfer-Encoding": {"8bit"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := part.Write([]byte(p.content)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@%s>\r\n", randomID(), domainOf(from))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n", writer.Boundary())
+	buf.WriteString("\r\n")
+	buf.Write(body.Bytes())
+
+	return buf.Bytes(), nil
+}
+
+func randomID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+func domainOf(address string) string {
+	address = strings.TrimSuffix(address, ">")
+	if i := strings.LastIndex(address, "@"); i >= 0 {
+		return address[i+1:]
+	}
+	return "localhost"
+}
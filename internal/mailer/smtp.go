@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"fmt"
+	"net/mail"
+	"net/smtp"
+)
+
+// SMTPMailer sends messages through an SMTP relay.
+type SMTPMailer struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	return &SMTPMailer{
+		Host:     host,
+		Port:     port,
+		Username: username,
+		Password: password,
+		From:     from,
+	}
+}
+
+func (m *SMTPMailer) Send(msg *Message) error {
+	data, err := buildMIME(m.From, msg)
+	if err != nil {
+		return err
+	}
+
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	addr := fmt.Sprintf("%s:%d", m.Host, m.Port)
+	return smtp.SendMail(addr, auth, from.Address, []string{msg.To}, data)
+}
@@ -0,0 +1,136 @@
+// Package passhash hashes passwords with Argon2id and verifies both Argon2id
+// and legacy bcrypt hashes.
+//
+// Argon2id hashes use the PHC string format:
+//
+//	$argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+//
+// with salt and hash in unpadded standard base64.
+package passhash
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrUnknownFormat is returned for hashes that are neither Argon2id PHC strings nor bcrypt.
+var ErrUnknownFormat = errors.New("passhash: unknown hash format")
+
+// Params are the Argon2id cost parameters. Memory is in KiB.
+type Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultParams follow the OWASP recommendation for Argon2id.
+var DefaultParams = Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// Hasher creates Argon2id hashes with fixed parameters.
+type Hasher struct {
+	params Params
+}
+
+// New returns a Hasher using params.
+func New(params Params) *Hasher {
+	return &Hasher{params: params}
+}
+
+// Hash returns the PHC-formatted Argon2id hash of password.
+func (h *Hasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.params.Memory, h.params.Iterations, h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify reports whether password matches encoded. needsRehash is true when
+// the password matches but the hash uses bcrypt or parameters other than the
+// Hasher's, so the caller should store a fresh Hash.
+func (h *Hasher) Verify(password, encoded string) (match, needsRehash bool, err error) {
+	if isBcrypt(encoded) {
+		err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, false, nil
+		}
+		if err != nil {
+			return false, false, err
+		}
+		return true, true, nil
+	}
+
+	params, salt, key, err := decode(encoded)
+	if err != nil {
+		return false, false, err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return false, false, nil
+	}
+
+	return true, params != h.params, nil
+}
+
+func isBcrypt(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+// decode parses a PHC-formatted Argon2id hash.
+func decode(encoded string) (Params, []byte, []byte, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return Params{}, nil, nil, ErrUnknownFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return Params{}, nil, nil, ErrUnknownFormat
+	}
+	if version != argon2.Version {
+		return Params{}, nil, nil, fmt.Errorf("passhash: unsupported argon2 version %d", version)
+	}
+
+	var params Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return Params{}, nil, nil, ErrUnknownFormat
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Params{}, nil, nil, ErrUnknownFormat
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return Params{}, nil, nil, ErrUnknownFormat
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+
+	return params, salt, key, nil
+}
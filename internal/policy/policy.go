@@ -0,0 +1,82 @@
+// Package policy decides what a role may do. Permissions are named
+// "<resource>.<action>" for global rights, and "<resource>.<action>.own" /
+// "<resource>.<action>.any" for rights that depend on owning the resource.
+package policy
+
+import (
+	"sync"
+	"time"
+)
+
+// PermissionLoader loads the permissions granted to each role, keyed by role name.
+type PermissionLoader interface {
+	GetAllPermissions() (map[string][]string, error)
+}
+
+// Policy answers permission checks from a cached copy of the role tables.
+type Policy struct {
+	loader PermissionLoader
+	ttl    time.Duration
+
+	mu       sync.RWMutex
+	grants   map[string]map[string]bool
+	loadedAt time.Time
+}
+
+func New(loader PermissionLoader, ttl time.Duration) *Policy {
+	return &Policy{loader: loader, ttl: ttl}
+}
+
+// Can reports whether role has been granted permission.
+func (p *Policy) Can(role, permission string) (bool, error) {
+	grants, err := p.load()
+	if err != nil {
+		return false, err
+	}
+
+	return grants[role][permission], nil
+}
+
+// CanOnOwned decides an ownership-scoped action such as "events.update":
+// it is allowed with "<action>.any", or with "<action>.own" when actorId owns the resource.
+func (p *Policy) CanOnOwned(role string, actorId, ownerId int, action string) (bool, error) {
+	grants, err := p.load()
+	if err != nil {
+		return false, err
+	}
+
+	if grants[role][action+".any"] {
+		return true, nil
+	}
+
+	return actorId == ownerId && grants[role][action+".own"], nil
+}
+
+func (p *Policy) load() (map[string]map[string]bool, error) {
+	p.mu.RLock()
+	grants, loadedAt := p.grants, p.loadedAt
+	p.mu.RUnlock()
+
+	if grants != nil && time.Since(loadedAt) < p.ttl {
+		return grants, nil
+	}
+
+	permissions, err := p.loader.GetAllPermissions()
+	if err != nil {
+		return nil, err
+	}
+
+	grants = make(map[string]map[string]bool, len(permissions))
+	for role, perms := range permissions {
+		grants[role] = make(map[string]bool, len(perms))
+		for _, perm := range perms {
+			grants[role][perm] = true
+		}
+	}
+
+	p.mu.Lock()
+	p.grants, p.loadedAt = grants, time.Now()
+	p.mu.Unlock()
+
+	return grants, nil
+}
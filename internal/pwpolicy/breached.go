@@ -0,0 +1,82 @@
+package pwpolicy
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// prefixLength is the number of hex characters of the SHA-1 hash used to pick
+// a range, as in the Pwned Passwords k-anonymity API.
+const prefixLength = 5
+
+// BreachedSet is an in-memory copy of a breached-password hash list, grouped
+// into ranges by SHA-1 prefix. Lookups never touch the network.
+type BreachedSet struct {
+	ranges map[string][]string
+}
+
+// LoadBreachedFile reads a file with one uppercase or lowercase SHA-1 hash per
+// line, optionally followed by ":<count>" as in the downloadable Pwned
+// Passwords list.
+func LoadBreachedFile(path string) (*BreachedSet, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	set := &BreachedSet{ranges: map[string][]string{}}
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		hash, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if hash == "" {
+			continue
+		}
+
+		hash = strings.ToUpper(hash)
+		if _, err := hex.DecodeString(hash); err != nil || len(hash) != sha1.Size*2 {
+			return nil, fmt.Errorf("%s:%d: not a SHA-1 hash", path, line)
+		}
+
+		prefix := hash[:prefixLength]
+		set.ranges[prefix] = append(set.ranges[prefix], hash[prefixLength:])
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, suffixes := range set.ranges {
+		sort.Strings(suffixes)
+	}
+
+	return set, nil
+}
+
+// Contains reports whether password is in the set. Like the k-anonymity API,
+// it selects the range for the hash prefix and searches it for the suffix.
+func (s *BreachedSet) Contains(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	suffixes := s.ranges[hash[:prefixLength]]
+	suffix := hash[prefixLength:]
+
+	i := sort.SearchStrings(suffixes, suffix)
+	return i < len(suffixes) && suffixes[i] == suffix
+}
+
+// Len returns the number of hashes in the set.
+func (s *BreachedSet) Len() int {
+	n := 0
+	for _, suffixes := range s.ranges {
+		n += len(suffixes)
+	}
+	return n
+}
@@ -0,0 +1,126 @@
+// Package pwpolicy decides whether a new password is acceptable: its length,
+// a list of banned passwords, similarity to the account's email or name, and
+// an offline breached-password check.
+package pwpolicy
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// minIdentifierLength is the shortest email local part or name fragment that
+// a password may not contain.
+const minIdentifierLength = 4
+
+// commonPasswords are always banned, in addition to any list loaded from a file.
+var commonPasswords = []string{
+	"password", "password1", "password123", "passw0rd", "12345678", "123456789",
+	"1234567890", "qwertyuiop", "qwerty123", "iloveyou", "letmein1", "welcome1",
+	"abc12345", "11111111", "00000000", "baseball", "football", "sunshine",
+	"superman", "trustno1", "princess", "dragon123", "monkey123", "admin123",
+}
+
+// Violations lists every rule a password broke.
+type Violations []string
+
+func (v Violations) Error() string {
+	return "password does not meet the policy: " + strings.Join(v, "; ")
+}
+
+// Policy holds the password rules.
+type Policy struct {
+	MinLength int
+	MaxLength int
+	banned    map[string]bool
+	breached  *BreachedSet
+}
+
+// New returns a policy with the given length limits, the built-in list of
+// common passwords and no breached-password check.
+func New(minLength, maxLength int) *Policy {
+	p := &Policy{MinLength: minLength, MaxLength: maxLength, banned: map[string]bool{}}
+	for _, password := range commonPasswords {
+		p.banned[password] = true
+	}
+	return p
+}
+
+// LoadBannedFile adds the passwords in path, one per line, to the banned list.
+func (p *Policy) LoadBannedFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			p.banned[strings.ToLower(line)] = true
+		}
+	}
+
+	return scanner.Err()
+}
+
+// SetBreached enables the breached-password check.
+func (p *Policy) SetBreached(set *BreachedSet) {
+	p.breached = set
+}
+
+// Check returns Violations if password breaks any rule for the account with
+// the given email and name, or nil if it is acceptable.
+func (p *Policy) Check(password, email, name string) error {
+	var violations Violations
+
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		violations = append(violations, "must be at least "+strconv.Itoa(p.MinLength)+" characters")
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violations = append(violations, "must be at most "+strconv.Itoa(p.MaxLength)+" characters")
+	}
+
+	lower := strings.ToLower(password)
+
+	if p.banned[lower] {
+		violations = append(violations, "is too common")
+	}
+
+	if containsIdentifier(lower, email, name) {
+		violations = append(violations, "must not contain your email or name")
+	}
+
+	if p.breached != nil && p.breached.Contains(password) {
+		violations = append(violations, "has appeared in a data breach")
+	}
+
+	if len(violations) > 0 {
+		return violations
+	}
+	return nil
+}
+
+// containsIdentifier reports whether the lowercased password contains the
+// local part of the email or any word of the name.
+func containsIdentifier(lower, email, name string) bool {
+	identifiers := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	if local, _, found := strings.Cut(strings.ToLower(email), "@"); found {
+		identifiers = append(identifiers, local)
+	}
+
+	for _, identifier := range identifiers {
+		if utf8.RuneCountInString(identifier) >= minIdentifierLength && strings.Contains(lower, identifier) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,347 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Expr is a parsed SCIM filter (RFC 7644 section 3.4.2.2).
+type Expr interface {
+	isExpr()
+}
+
+// AttrExpr compares an attribute with a value, e.g. userName eq "bjensen".
+// Value is a string, float64, bool or nil; it is unused for the "pr" operator.
+type AttrExpr struct {
+	Path  string
+	Op    string
+	Value any
+}
+
+// LogicalExpr joins two filters with "and" or "or".
+type LogicalExpr struct {
+	Op          string
+	Left, Right Expr
+}
+
+// NotExpr negates a filter.
+type NotExpr struct {
+	X Expr
+}
+
+// ValuePathExpr filters the values of a multi-valued attribute, e.g.
+// emails[type eq "work"]. Paths inside Filter are relative to Path.
+type ValuePathExpr struct {
+	Path   string
+	Filter Expr
+}
+
+func (*AttrExpr) isExpr()      {}
+func (*LogicalExpr) isExpr()   {}
+func (*NotExpr) isExpr()       {}
+func (*ValuePathExpr) isExpr() {}
+
+var compareOps = map[string]bool{
+	"eq": true, "ne": true, "co": true, "sw": true, "ew": true,
+	"gt": true, "ge": true, "lt": true, "le": true,
+}
+
+// ParseFilter parses a filter expression. Attribute paths are returned in
+// lower case with any schema URN prefix removed, since SCIM attribute names
+// are case-insensitive.
+func ParseFilter(filter string) (Expr, error) {
+	p := &parser{tokens: tokenize(filter)}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, invalidFilter("unexpected %q", tok.text)
+	}
+
+	return expr, nil
+}
+
+// Path is a parsed PATCH operation path such as members[value eq "2"] or
+// name.givenName.
+type Path struct {
+	Attr    string
+	Filter  Expr
+	SubAttr string
+}
+
+// ParsePath parses a PATCH operation path. Names are returned in lower case.
+func ParsePath(path string) (*Path, error) {
+	p := &parser{tokens: tokenize(path)}
+
+	tok := p.next()
+	if tok.kind != tokenWord {
+		return nil, &Error{Status: 400, Type: ErrorInvalidPath, Detail: "invalid path " + path}
+	}
+
+	result := &Path{Attr: normalizePath(tok.text)}
+
+	if p.peek().kind == tokenLBracket {
+		p.next()
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, &Error{Status: 400, Type: ErrorInvalidPath, Detail: "invalid path " + path}
+		}
+		if p.next().kind != tokenRBracket {
+			return nil, &Error{Status: 400, Type: ErrorInvalidPath, Detail: "invalid path " + path}
+		}
+		result.Filter = filter
+
+		// members[value eq "2"].display 的子屬性會被切成以 "." 開頭的 word
+		if tok := p.peek(); tok.kind == tokenWord && strings.HasPrefix(tok.text, ".") {
+			p.next()
+			result.SubAttr = strings.ToLower(strings.TrimPrefix(tok.text, "."))
+		}
+	} else if attr, sub, found := strings.Cut(result.Attr, "."); found {
+		result.Attr, result.SubAttr = attr, sub
+	}
+
+	if p.peek().kind != tokenEOF {
+		return nil, &Error{Status: 400, Type: ErrorInvalidPath, Detail: "invalid path " + path}
+	}
+
+	return result, nil
+}
+
+// normalizePath lowercases an attribute path and strips a schema URN prefix
+// such as urn:ietf:params:scim:schemas:core:2.0:User:.
+func normalizePath(path string) string {
+	if i := strings.LastIndex(path, ":"); i >= 0 && strings.HasPrefix(strings.ToLower(path), "urn:") {
+		path = path[i+1:]
+	}
+	return strings.ToLower(path)
+}
+
+func invalidFilter(format string, args ...any) error {
+	return &Error{Status: 400, Type: ErrorInvalidFilter, Detail: fmt.Sprintf(format, args...)}
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenLParen
+	tokenRParen
+	tokenLBracket
+	tokenRBracket
+	tokenInvalid
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value string
+}
+
+func tokenize(s string) []token {
+	var tokens []token
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")"})
+			i++
+		case c == '[':
+			tokens = append(tokens, token{kind: tokenLBracket, text: "["})
+			i++
+		case c == ']':
+			tokens = append(tokens, token{kind: tokenRBracket, text: "]"})
+			i++
+		case c == '"':
+			// 字串採 JSON 語法，交給 encoding/json 處理跳脫字元
+			j := i + 1
+			for j < len(s) && s[j] != '"' {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				return append(tokens, token{kind: tokenInvalid, text: s[i:]})
+			}
+			var value string
+			if err := json.Unmarshal([]byte(s[i:j+1]), &value); err != nil {
+				return append(tokens, token{kind: tokenInvalid, text: s[i : j+1]})
+			}
+			tokens = append(tokens, token{kind: tokenString, text: s[i : j+1], value: value})
+			i = j + 1
+		default:
+			j := i
+			for j < len(s) && !strings.ContainsRune(" \t\n\r()[]\"", rune(s[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: s[i:j]})
+			i = j
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF})
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) peekKeyword(keyword string) bool {
+	tok := p.peek()
+	return tok.kind == tokenWord && strings.EqualFold(tok.text, keyword)
+}
+
+// parseOr 與 parseAnd 依 RFC 7644 的優先順序處理：not > and > or
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peekKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &LogicalExpr{Op: "or", Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peekKeyword("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &LogicalExpr{Op: "and", Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	if p.peekKeyword("not") {
+		p.next()
+		if p.peek().kind != tokenLParen {
+			return nil, invalidFilter("expected ( after not")
+		}
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &NotExpr{X: x}, nil
+	}
+
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenLParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tokenRParen {
+			return nil, invalidFilter("missing )")
+		}
+		return expr, nil
+	case tokenWord:
+		return p.parseAttr(normalizePath(tok.text))
+	case tokenEOF:
+		return nil, invalidFilter("unexpected end of filter")
+	default:
+		return nil, invalidFilter("unexpected %q", tok.text)
+	}
+}
+
+func (p *parser) parseAttr(path string) (Expr, error) {
+	if p.peek().kind == tokenLBracket {
+		p.next()
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tokenRBracket {
+			return nil, invalidFilter("missing ]")
+		}
+		return &ValuePathExpr{Path: path, Filter: filter}, nil
+	}
+
+	if !isAttrName(path) {
+		return nil, invalidFilter("invalid attribute %q", path)
+	}
+
+	opTok := p.next()
+	op := strings.ToLower(opTok.text)
+
+	if opTok.kind == tokenWord && op == "pr" {
+		return &AttrExpr{Path: path, Op: op}, nil
+	}
+
+	if opTok.kind != tokenWord || !compareOps[op] {
+		return nil, invalidFilter("unknown operator %q", opTok.text)
+	}
+
+	valueTok := p.next()
+	switch valueTok.kind {
+	case tokenString:
+		return &AttrExpr{Path: path, Op: op, Value: valueTok.value}, nil
+	case tokenWord:
+		var value any
+		if err := json.Unmarshal([]byte(strings.ToLower(valueTok.text)), &value); err != nil {
+			return nil, invalidFilter("invalid value %q", valueTok.text)
+		}
+		if _, isString := value.(string); isString {
+			return nil, invalidFilter("invalid value %q", valueTok.text)
+		}
+		return &AttrExpr{Path: path, Op: op, Value: value}, nil
+	default:
+		return nil, invalidFilter("missing value after %q", opTok.text)
+	}
+}
+
+func isAttrName(path string) bool {
+	if path == "" {
+		return false
+	}
+	for _, r := range path {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.' && r != '_' && r != '-' && r != '$' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package scim
+
+import "strings"
+
+// Match evaluates expr in memory against a resource, or against one value of
+// a multi-valued attribute such as a group member. Attribute names in attrs
+// are lower case; multi-valued attributes are []map[string]any.
+func Match(expr Expr, attrs map[string]any) bool {
+	switch e := expr.(type) {
+	case *LogicalExpr:
+		if e.Op == "and" {
+			return Match(e.Left, attrs) && Match(e.Right, attrs)
+		}
+		return Match(e.Left, attrs) || Match(e.Right, attrs)
+
+	case *NotExpr:
+		return !Match(e.X, attrs)
+
+	case *ValuePathExpr:
+		values, _ := attrs[e.Path].([]map[string]any)
+		for _, value := range values {
+			if Match(e.Filter, value) {
+				return true
+			}
+		}
+		return false
+
+	case *AttrExpr:
+		value, ok := attrs[e.Path]
+		if e.Op == "pr" {
+			return ok && value != nil && value != ""
+		}
+		if !ok {
+			return false
+		}
+		return matchValue(e.Op, value, e.Value)
+	}
+
+	return false
+}
+
+func matchValue(op string, actual, want any) bool {
+	switch a := actual.(type) {
+	case string:
+		w, ok := want.(string)
+		if !ok {
+			return false
+		}
+		a, w = strings.ToLower(a), strings.ToLower(w)
+		switch op {
+		case "eq":
+			return a == w
+		case "ne":
+			return a != w
+		case "co":
+			return strings.Contains(a, w)
+		case "sw":
+			return strings.HasPrefix(a, w)
+		case "ew":
+			return strings.HasSuffix(a, w)
+		case "gt":
+			return a > w
+		case "ge":
+			return a >= w
+		case "lt":
+			return a < w
+		case "le":
+			return a <= w
+		}
+	case bool:
+		w, ok := want.(bool)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "eq":
+			return a == w
+		case "ne":
+			return a != w
+		}
+	}
+
+	return false
+}
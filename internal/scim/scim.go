@@ -0,0 +1,173 @@
+// Package scim implements the protocol pieces of SCIM 2.0 (RFC 7643 and
+// RFC 7644) that do not depend on how resources are stored: filter and path
+// parsing, filter translation to SQL, PATCH requests, list responses and
+// errors.
+package scim
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema URNs.
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+)
+
+// ContentType is the media type of SCIM requests and responses.
+const ContentType = "application/scim+json"
+
+// Error types (the scimType member of an error response).
+const (
+	ErrorInvalidFilter = "invalidFilter"
+	ErrorInvalidPath   = "invalidPath"
+	ErrorInvalidSyntax = "invalidSyntax"
+	ErrorInvalidValue  = "invalidValue"
+	ErrorNoTarget      = "noTarget"
+	ErrorMutability    = "mutability"
+	ErrorUniqueness    = "uniqueness"
+)
+
+// Error is a SCIM error response. It is also returned as a Go error by the
+// parsers in this package.
+type Error struct {
+	Status int
+	Type   string
+	Detail string
+}
+
+func (e *Error) Error() string {
+	return "scim: " + e.Detail
+}
+
+// MarshalJSON renders the error in the SCIM wire format, where status is a string.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Schemas []string `json:"schemas"`
+		Status  string   `json:"status"`
+		Type    string   `json:"scimType,omitempty"`
+		Detail  string   `json:"detail,omitempty"`
+	}{[]string{SchemaError}, strconv.Itoa(e.Status), e.Type, e.Detail})
+}
+
+// Meta is the meta attribute of a resource.
+type Meta struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Location     string     `json:"location,omitempty"`
+}
+
+// ListResponse is the body of a query response.
+type ListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    any      `json:"Resources"`
+}
+
+// NewListResponse wraps one page of resources.
+func NewListResponse(resources any, count, total, startIndex int) *ListResponse {
+	return &ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: count,
+		Resources:    resources,
+	}
+}
+
+// PatchRequest is the body of a PATCH request.
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// PatchOperation is one add, replace or remove operation. Op is lower case
+// after Validate.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Validate checks the message schema and operations and normalizes the
+// operation names, which some clients send capitalized.
+func (r *PatchRequest) Validate() error {
+	if !hasSchema(r.Schemas, SchemaPatchOp) {
+		return &Error{Status: 400, Type: ErrorInvalidSyntax, Detail: "request must use the " + SchemaPatchOp + " schema"}
+	}
+
+	if len(r.Operations) == 0 {
+		return &Error{Status: 400, Type: ErrorInvalidSyntax, Detail: "no operations"}
+	}
+
+	for i := range r.Operations {
+		op := &r.Operations[i]
+		op.Op = strings.ToLower(op.Op)
+
+		switch op.Op {
+		case "add", "replace":
+			if len(op.Value) == 0 {
+				return &Error{Status: 400, Type: ErrorInvalidValue, Detail: op.Op + " operation needs a value"}
+			}
+		case "remove":
+			if op.Path == "" {
+				return &Error{Status: 400, Type: ErrorNoTarget, Detail: "remove operation needs a path"}
+			}
+		default:
+			return &Error{Status: 400, Type: ErrorInvalidSyntax, Detail: "unknown operation " + op.Op}
+		}
+	}
+
+	return nil
+}
+
+func hasSchema(schemas []string, schema string) bool {
+	for _, s := range schemas {
+		if strings.EqualFold(s, schema) {
+			return true
+		}
+	}
+	return false
+}
+
+// DecodeBool decodes a boolean value, also accepting the strings "true" and "false"
+// that some clients send.
+func DecodeBool(raw json.RawMessage) (bool, error) {
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return false, &Error{Status: 400, Type: ErrorInvalidValue, Detail: "invalid boolean"}
+	}
+
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		if strings.EqualFold(v, "true") {
+			return true, nil
+		}
+		if strings.EqualFold(v, "false") {
+			return false, nil
+		}
+	}
+
+	return false, &Error{Status: 400, Type: ErrorInvalidValue, Detail: "invalid boolean"}
+}
+
+// DecodeString decodes a string value.
+func DecodeString(raw json.RawMessage) (string, error) {
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", &Error{Status: 400, Type: ErrorInvalidValue, Detail: "invalid string"}
+	}
+	return value, nil
+}
@@ -0,0 +1,180 @@
+package scim
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AttrType selects how an attribute is compared in SQL.
+type AttrType int
+
+const (
+	// String attributes compare case-insensitively.
+	String AttrType = iota
+	// ExactString attributes compare case-sensitively.
+	ExactString
+	Boolean
+	Integer
+	DateTime
+)
+
+// Column maps a filterable attribute to a SQL expression.
+type Column struct {
+	SQL  string
+	Type AttrType
+}
+
+// Columns maps lower-case attribute paths, such as "username" or
+// "emails.value", to columns. Attributes that are not listed cannot be
+// filtered on.
+type Columns map[string]Column
+
+// ToSQL translates expr into a boolean SQL expression over columns. Values
+// are passed as arguments numbered from $firstArg, so the result can be
+// appended to a query that already has firstArg-1 arguments.
+func ToSQL(expr Expr, columns Columns, firstArg int) (string, []any, error) {
+	b := &sqlBuilder{columns: columns, firstArg: firstArg}
+
+	cond, err := b.build(expr, "")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return cond, b.args, nil
+}
+
+type sqlBuilder struct {
+	columns  Columns
+	firstArg int
+	args     []any
+}
+
+func (b *sqlBuilder) arg(value any) string {
+	b.args = append(b.args, value)
+	return "$" + strconv.Itoa(b.firstArg+len(b.args)-1)
+}
+
+func (b *sqlBuilder) build(expr Expr, prefix string) (string, error) {
+	switch e := expr.(type) {
+	case *LogicalExpr:
+		left, err := b.build(e.Left, prefix)
+		if err != nil {
+			return "", err
+		}
+		right, err := b.build(e.Right, prefix)
+		if err != nil {
+			return "", err
+		}
+		return "(" + left + " " + strings.ToUpper(e.Op) + " " + right + ")", nil
+
+	case *NotExpr:
+		x, err := b.build(e.X, prefix)
+		if err != nil {
+			return "", err
+		}
+		return "NOT COALESCE(" + x + ", false)", nil
+
+	case *ValuePathExpr:
+		// 每個資源的多值屬性在資料表中只有一個值，因此 emails[type eq "work"] 等同於對該值過濾
+		return b.build(e.Filter, e.Path+".")
+
+	case *AttrExpr:
+		return b.compare(e, prefix+e.Path)
+	}
+
+	return "", invalidFilter("unsupported expression")
+}
+
+var sqlOps = map[string]string{"eq": "=", "ne": "<>", "gt": ">", "ge": ">=", "lt": "<", "le": "<="}
+
+func (b *sqlBuilder) compare(e *AttrExpr, path string) (string, error) {
+	column, ok := b.columns[path]
+	if !ok {
+		return "", invalidFilter("filtering on %q is not supported", path)
+	}
+
+	if e.Op == "pr" {
+		if column.Type == String || column.Type == ExactString {
+			return "COALESCE(" + column.SQL + ", '') <> ''", nil
+		}
+		return column.SQL + " IS NOT NULL", nil
+	}
+
+	switch column.Type {
+	case String, ExactString:
+		value, ok := e.Value.(string)
+		if !ok {
+			return "", invalidFilter("%q needs a string value", path)
+		}
+
+		col := column.SQL
+		if column.Type == String {
+			col = "LOWER(" + col + ")"
+			value = strings.ToLower(value)
+		}
+
+		switch e.Op {
+		case "co":
+			return col + " LIKE " + b.arg("%"+escapeLike(value)+"%"), nil
+		case "sw":
+			return col + " LIKE " + b.arg(escapeLike(value)+"%"), nil
+		case "ew":
+			return col + " LIKE " + b.arg("%"+escapeLike(value)), nil
+		default:
+			return col + " " + sqlOps[e.Op] + " " + b.arg(value), nil
+		}
+
+	case Boolean:
+		value, ok := e.Value.(bool)
+		if !ok || (e.Op != "eq" && e.Op != "ne") {
+			return "", invalidFilter("%q only supports eq and ne with true or false", path)
+		}
+		return column.SQL + " " + sqlOps[e.Op] + " " + b.arg(value), nil
+
+	case Integer:
+		var value int
+		switch v := e.Value.(type) {
+		case float64:
+			value = int(v)
+		case string:
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				// 非數字的 id 不會對應到任何資源
+				if e.Op == "ne" {
+					return "true", nil
+				}
+				return "false", nil
+			}
+			value = n
+		default:
+			return "", invalidFilter("%q needs a number", path)
+		}
+		op, ok := sqlOps[e.Op]
+		if !ok {
+			return "", invalidFilter("operator %q is not supported for %q", e.Op, path)
+		}
+		return column.SQL + " " + op + " " + b.arg(value), nil
+
+	case DateTime:
+		s, _ := e.Value.(string)
+		value, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return "", invalidFilter("%q needs an RFC 3339 timestamp", path)
+		}
+		op, ok := sqlOps[e.Op]
+		if !ok {
+			return "", invalidFilter("operator %q is not supported for %q", e.Op, path)
+		}
+		return column.SQL + " " + op + " " + b.arg(value), nil
+	}
+
+	return "", fmt.Errorf("scim: unknown column type for %q", path)
+}
+
+// escapeLike escapes the LIKE wildcards in s; PostgreSQL's default escape
+// character is a backslash.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,225 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Key is a single signing key identified by its kid.
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+
+	// signKey is the secret or private key, verifyKey the secret or public key.
+	signKey   any
+	verifyKey any
+}
+
+// KeySet signs tokens with one active key and verifies tokens against any
+// key it holds, so old keys keep validating tokens during a rotation.
+type KeySet struct {
+	method jwt.SigningMethod
+	active *Key
+	keys   map[string]*Key
+}
+
+// NewHMAC returns a KeySet that signs and verifies with a shared HS256 secret.
+func NewHMAC(secret string) *KeySet {
+	key := &Key{
+		ID:        "",
+		Method:    jwt.SigningMethodHS256,
+		signKey:   []byte(secret),
+		verifyKey: []byte(secret),
+	}
+
+	return &KeySet{
+		method: jwt.SigningMethodHS256,
+		active: key,
+		keys:   map[string]*Key{"": key},
+	}
+}
+
+// LoadDir loads every PEM private key in dir for the given algorithm ("RS256"
+// or "EdDSA"). The kid of a key is its file name without the .pem extension.
+// activeKid selects the signing key; when empty the last kid in lexical order
+// is used, so naming keys by date rotates automatically.
+func LoadDir(alg, dir, activeKid string) (*KeySet, error) {
+	method := jwt.GetSigningMethod(alg)
+	if method == nil || (alg != "RS256" && alg != "EdDSA") {
+		return nil, fmt.Errorf("unsupported signing method %q", alg)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no signing keys found in %s", dir)
+	}
+
+	sort.Strings(paths)
+
+	ks := &KeySet{method: method, keys: make(map[string]*Key)}
+
+	for _, path := range paths {
+		kid := strings.TrimSuffix(filepath.Base(path), ".pem")
+
+		key, err := loadKey(kid, path, method)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		ks.keys[kid] = key
+		if activeKid == "" {
+			ks.active = key
+		}
+	}
+
+	if activeKid != "" {
+		active, ok := ks.keys[activeKid]
+		if !ok {
+			return nil, fmt.Errorf("active signing key %q not found in %s", activeKid, dir)
+		}
+		ks.active = active
+	}
+
+	return ks, nil
+}
+
+func loadKey(kid, path string, method jwt.SigningMethod) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var private any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := private.(type) {
+	case *rsa.PrivateKey:
+		if method != jwt.SigningMethodRS256 {
+			return nil, errors.New("RSA key does not match signing method")
+		}
+		return &Key{ID: kid, Method: method, signKey: k, verifyKey: &k.PublicKey}, nil
+	case ed25519.PrivateKey:
+		if method != jwt.SigningMethodEdDSA {
+			return nil, errors.New("Ed25519 key does not match signing method")
+		}
+		return &Key{ID: kid, Method: method, signKey: k, verifyKey: k.Public()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", private)
+	}
+}
+
+// Method returns the algorithm name tokens are signed with.
+func (ks *KeySet) Method() string {
+	return ks.method.Alg()
+}
+
+// Sign signs claims with the active key and sets the kid header.
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.active.Method, claims)
+	if ks.active.ID != "" {
+		token.Header["kid"] = ks.active.ID
+	}
+	return token.SignedString(ks.active.signKey)
+}
+
+// Keyfunc resolves the verification key for a token by its kid header.
+func (ks *KeySet) Keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, jwt.ErrTokenSignatureInvalid
+	}
+
+	return key.verifyKey, nil
+}
+
+// JWK is a public key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet is the document served from /.well-known/jwks.json.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of the set. Symmetric keys are never published.
+func (ks *KeySet) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+
+	kids := make([]string, 0, len(ks.keys))
+	for kid := range ks.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	for _, kid := range kids {
+		if jwk, ok := publicJWK(ks.keys[kid]); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+
+	return set
+}
+
+func publicJWK(key *Key) (JWK, bool) {
+	jwk := JWK{Kid: key.ID, Use: "sig", Alg: key.Method.Alg()}
+
+	switch pub := key.verifyKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	default:
+		return JWK{}, false
+	}
+
+	return jwk, true
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Local stores files in a directory on the local disk. The files are served
+// by the application itself under BaseURL.
+type Local struct {
+	Dir     string
+	BaseURL string
+}
+
+func NewLocal(dir, baseURL string) (*Local, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &Local{Dir: dir, BaseURL: strings.TrimSuffix(baseURL, "/")}, nil
+}
+
+func (s *Local) Put(key string, r io.Reader, contentType string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Join(s.Dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+
+	// 先寫入暫存檔再改名，讀取端不會看到寫到一半的檔案
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), name)
+}
+
+func (s *Local) Delete(key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(filepath.Join(s.Dir, filepath.FromSlash(key)))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (s *Local) URL(key string) string {
+	return s.BaseURL + "/" + key
+}
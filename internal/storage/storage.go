@@ -0,0 +1,39 @@
+// Package storage stores uploaded files such as avatars behind a small
+// interface so that the backend can be swapped without touching handlers.
+package storage
+
+import (
+	"errors"
+	"io"
+	"path"
+	"strings"
+)
+
+// ErrInvalidKey is returned for keys that are empty, absolute or try to
+// escape the storage root.
+var ErrInvalidKey = errors.New("storage: invalid key")
+
+// Storage saves and serves files under slash-separated keys such as
+// "avatars/42/abc-256.png".
+type Storage interface {
+	// Put stores the contents of r under key, replacing any existing file.
+	Put(key string, r io.Reader, contentType string) error
+	// Delete removes the file stored under key. Missing files are not an error.
+	Delete(key string) error
+	// URL returns the public URL of the file stored under key.
+	URL(key string) string
+}
+
+// cleanKey validates key and returns it in canonical form.
+func cleanKey(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, `\`) {
+		return "", ErrInvalidKey
+	}
+
+	cleaned := path.Clean(key)
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", ErrInvalidKey
+	}
+
+	return cleaned, nil
+}
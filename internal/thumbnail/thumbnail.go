@@ -0,0 +1,84 @@
+// Package thumbnail decodes uploaded images and scales them down to square
+// thumbnails using only the standard library.
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+var (
+	// ErrUnsupportedFormat is returned for data that is not a JPEG, PNG or GIF image.
+	ErrUnsupportedFormat = errors.New("thumbnail: unsupported image format")
+	// ErrTooLarge is returned for images with more pixels than allowed.
+	ErrTooLarge = errors.New("thumbnail: image dimensions too large")
+)
+
+// Decode decodes a JPEG, PNG or GIF image. The header is checked before
+// decoding so that images with more than maxPixels pixels are rejected
+// without allocating them.
+func Decode(data []byte, maxPixels int) (image.Image, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		if errors.Is(err, image.ErrFormat) {
+			return nil, ErrUnsupportedFormat
+		}
+		return nil, err
+	}
+
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// Square crops the centre square out of src and scales it to size x size
+// pixels, averaging the source pixels that fall into each output pixel.
+// Images smaller than size are cropped but not enlarged.
+func Square(src image.Image, size int) *image.RGBA {
+	bounds := src.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	size = min(size, side)
+
+	x0 := bounds.Min.X + (bounds.Dx()-side)/2
+	y0 := bounds.Min.Y + (bounds.Dy()-side)/2
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+
+	for y := range size {
+		sy0 := y0 + y*side/size
+		sy1 := max(y0+(y+1)*side/size, sy0+1)
+
+		for x := range size {
+			sx0 := x0 + x*side/size
+			sx1 := max(x0+(x+1)*side/size, sx0+1)
+
+			// RGBA() 回傳預乘 alpha 的值，直接平均即可
+			var r, g, b, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					b += uint64(pb)
+					a += uint64(pa)
+					n++
+				}
+			}
+
+			i := dst.PixOffset(x, y)
+			dst.Pix[i+0] = uint8(r / n >> 8)
+			dst.Pix[i+1] = uint8(g / n >> 8)
+			dst.Pix[i+2] = uint8(b / n >> 8)
+			dst.Pix[i+3] = uint8(a / n >> 8)
+		}
+	}
+
+	return dst
+}